@@ -6,7 +6,9 @@ iteration=$3
 tf_version=$4
 tf_path=$5
 
-go get ${project}
+ldflags="-X ${project}/signalform.ProviderVersion=${version}"
+
+go get -ldflags "${ldflags}" ${project}
 
 [[ -d /dist ]] || mkdir /dist
 cd /dist
@@ -19,6 +21,6 @@ fpm \
     --version ${version} \
     /go/bin/${project}="${tf_path}"/bin/
 
-env GOOS=${GOOS} GOARCH=${GOARCH} go build -v \
+env GOOS=${GOOS} GOARCH=${GOARCH} go build -v -ldflags "${ldflags}" \
     -o /dist/terraform-provider-signalform-${GOOS}_${GOARCH} \
     terraform-provider-signalform
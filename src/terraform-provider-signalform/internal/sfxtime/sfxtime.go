@@ -0,0 +1,53 @@
+/*
+  Package sfxtime parses and validates the SignalFx relative time syntax (e.g. -5m, -1h, -1d, -1w, or
+  compound values such as -1h30m), shared by every schema accepting a time range.
+*/
+package sfxtime
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+var relativeTimeRegexp = regexp.MustCompile("^-(?:[0-9]+[mhdw])+$")
+
+var relativeTimeComponentRegexp = regexp.MustCompile("([0-9]+)([mhdw])")
+
+var unitMilliseconds = map[string]int{
+	"m": 60 * 1000,
+	"h": 60 * 60 * 1000,
+	"d": 24 * 60 * 60 * 1000,
+	"w": 7 * 24 * 60 * 60 * 1000,
+}
+
+/*
+  Converts a SignalFx relative time into milliseconds (e.g. -15m is 900000, -1h30m is 5400000)
+*/
+func ToMilliseconds(relativeTime string) (int, error) {
+	if !relativeTimeRegexp.MatchString(relativeTime) {
+		return -1, fmt.Errorf("%s not allowed. Please use SignalFx time syntax (e.g. -5m, -1h, -1h30m)", relativeTime)
+	}
+	total := 0
+	for _, matches := range relativeTimeComponentRegexp.FindAllStringSubmatch(relativeTime, -1) {
+		amount, err := strconv.Atoi(matches[1])
+		if err != nil {
+			return -1, fmt.Errorf("%s not allowed: %s", relativeTime, err.Error())
+		}
+		total += amount * unitMilliseconds[matches[2]]
+	}
+	if total == 0 {
+		return -1, fmt.Errorf("%s not allowed; the time range must be greater than zero", relativeTime)
+	}
+	return total, nil
+}
+
+/*
+  Schema ValidateFunc for SignalFx relative time fields
+*/
+func Validate(v interface{}, k string) (we []string, errors []error) {
+	if _, err := ToMilliseconds(v.(string)); err != nil {
+		errors = append(errors, err)
+	}
+	return
+}
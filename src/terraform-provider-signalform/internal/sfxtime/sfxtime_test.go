@@ -0,0 +1,71 @@
+package sfxtime
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToMilliseconds(t *testing.T) {
+	cases := map[string]int{
+		"-1m":    60000,
+		"-15m":   900000,
+		"-1h":    3600000,
+		"-12h":   43200000,
+		"-1d":    86400000,
+		"-7d":    604800000,
+		"-1w":    604800000,
+		"-2w":    1209600000,
+		"-090m":  5400000,
+		"-1h30m": 5400000,
+		"-1d12h": 129600000,
+		"-0h5m":  300000,
+	}
+	for relativeTime, expected := range cases {
+		ms, err := ToMilliseconds(relativeTime)
+		assert.Nil(t, err, relativeTime)
+		assert.Equal(t, expected, ms, relativeTime)
+	}
+}
+
+func TestToMillisecondsNotAllowed(t *testing.T) {
+	for _, relativeTime := range []string{
+		"",
+		"-",
+		"-m",
+		"5m",
+		"+5m",
+		"-5M",
+		"-5s",
+		"-5y",
+		"-5 m",
+		" -5m",
+		"-5m ",
+		"-5mm",
+		"-1h-30m",
+		"-1h30",
+		"-0h0m",
+		"-1.5h",
+		"now-5m",
+		"1518000000000",
+		"-0m",
+		"-99999999999999999999m",
+	} {
+		ms, err := ToMilliseconds(relativeTime)
+		assert.NotNil(t, err, relativeTime)
+		assert.Equal(t, -1, ms, relativeTime)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, relativeTime := range []string{"-5m", "-5h", "-5d", "-5w", "-1w2d"} {
+		_, errors := Validate(relativeTime, "time_range")
+		assert.Equal(t, 0, len(errors), relativeTime)
+	}
+}
+
+func TestValidateNotAllowed(t *testing.T) {
+	_, errors := Validate("-5M", "time_range")
+	assert.Equal(t, 1, len(errors))
+	assert.Contains(t, errors[0].Error(), "-5M not allowed")
+}
@@ -2,10 +2,21 @@ package main
 
 import (
 	"github.com/hashicorp/terraform/plugin"
+	"os"
 	"terraform-provider-signalform/signalform"
 )
 
 func main() {
+	// Terraform runs the plugin without arguments, anything else is a command run by hand
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "backup":
+			os.Exit(signalform.Backup(os.Args[2:]))
+		case "restore":
+			os.Exit(signalform.Restore(os.Args[2:]))
+		}
+	}
+
 	plugin.Serve(&plugin.ServeOpts{
 		ProviderFunc: signalform.Provider,
 	})
@@ -0,0 +1,105 @@
+package signalform
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+/*
+  CloudWatch namespaces of the AWS services whose metrics the SignalFx AWS integration syncs
+*/
+var AWSServiceNamespaces = []string{
+	"AWS/ApiGateway",
+	"AWS/ApplicationELB",
+	"AWS/AutoScaling",
+	"AWS/Billing",
+	"AWS/CloudFront",
+	"AWS/DynamoDB",
+	"AWS/EBS",
+	"AWS/EC2",
+	"AWS/ECS",
+	"AWS/EFS",
+	"AWS/ELB",
+	"AWS/ES",
+	"AWS/ElastiCache",
+	"AWS/ElasticBeanstalk",
+	"AWS/ElasticMapReduce",
+	"AWS/Events",
+	"AWS/Firehose",
+	"AWS/Kinesis",
+	"AWS/Lambda",
+	"AWS/Logs",
+	"AWS/NATGateway",
+	"AWS/NetworkELB",
+	"AWS/RDS",
+	"AWS/Redshift",
+	"AWS/Route53",
+	"AWS/S3",
+	"AWS/SES",
+	"AWS/SNS",
+	"AWS/SQS",
+	"AWS/States",
+	"AWS/VPN",
+}
+
+/*
+  Data source listing the AWS service namespaces supported by the AWS integration. Namespaces given in
+  names are checked against them, so that typos are reported at plan time.
+*/
+func awsServicesDataSource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"names": &schema.Schema{
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Namespaces to check (e.g. AWS/EC2). All the supported namespaces are listed if not set",
+			},
+			"namespaces": &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The namespaces of names, or all the supported namespaces",
+			},
+		},
+
+		Read: awsServicesDataSourceRead,
+	}
+}
+
+func awsServicesDataSourceRead(d *schema.ResourceData, meta interface{}) error {
+	names := make([]string, 0)
+	for _, name := range d.Get("names").([]interface{}) {
+		names = append(names, name.(string))
+	}
+	namespaces, err := getAWSServiceNamespaces(names)
+	if err != nil {
+		return err
+	}
+	d.SetId(strings.Join(namespaces, ","))
+	return d.Set("namespaces", namespaces)
+}
+
+func getAWSServiceNamespaces(names []string) ([]string, error) {
+	if len(names) == 0 {
+		return AWSServiceNamespaces, nil
+	}
+	supported := make(map[string]bool)
+	for _, namespace := range AWSServiceNamespaces {
+		supported[namespace] = true
+	}
+	unknown := make([]string, 0)
+	for _, name := range names {
+		if !supported[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("Unsupported AWS namespaces %s; must be some of: %s", strings.Join(unknown, ", "), strings.Join(AWSServiceNamespaces, ", "))
+	}
+	return names, nil
+}
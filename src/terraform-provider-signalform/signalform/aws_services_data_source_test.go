@@ -0,0 +1,24 @@
+package signalform
+
+import (
+	"github.com/stretchr/testify/assert"
+	"sort"
+	"testing"
+)
+
+func TestAWSServiceNamespacesSorted(t *testing.T) {
+	assert.True(t, sort.StringsAreSorted(AWSServiceNamespaces))
+}
+
+func TestGetAWSServiceNamespaces(t *testing.T) {
+	namespaces, err := getAWSServiceNamespaces([]string{})
+	assert.Nil(t, err)
+	assert.Equal(t, AWSServiceNamespaces, namespaces)
+
+	namespaces, err = getAWSServiceNamespaces([]string{"AWS/EC2", "AWS/RDS"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"AWS/EC2", "AWS/RDS"}, namespaces)
+
+	_, err = getAWSServiceNamespaces([]string{"AWS/EC2", "AWS/Ec2", "EC2"})
+	assert.Contains(t, err.Error(), "Unsupported AWS namespaces AWS/Ec2, EC2")
+}
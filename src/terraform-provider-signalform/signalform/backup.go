@@ -0,0 +1,187 @@
+package signalform
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+/*
+  Kinds of objects stored in a backup, mapped to their API URL. The kind is also the name of the
+  backup sub-directory holding the objects.
+*/
+var BackupKinds = map[string]string{
+	"chart":          CHART_API_URL,
+	"dashboard":      DASHBOARD_API_URL,
+	"dashboardgroup": DASHBOARD_GROUP_API_URL,
+	"detector":       DETECTOR_API_URL,
+}
+
+/*
+  Terraform resource types whose objects are backed up, mapped to their backup kind
+*/
+var BackupResourceKinds = map[string]string{
+	"signalform_time_chart":         "chart",
+	"signalform_heatmap_chart":      "chart",
+	"signalform_single_value_chart": "chart",
+	"signalform_list_chart":         "chart",
+	"signalform_table_chart":        "chart",
+	"signalform_text_chart":         "chart",
+	"signalform_log_view_chart":     "chart",
+	"signalform_log_timeline_chart": "chart",
+	"signalform_dashboard":          "dashboard",
+	"signalform_dashboard_group":    "dashboardgroup",
+	"signalform_detector":           "detector",
+	"signalform_heartbeat_detector": "detector",
+}
+
+type backupObject struct {
+	Kind string
+	Id   string
+}
+
+/*
+  Entry point of the "backup" command. It snapshots the API JSON of every Signalform managed object
+  found in a terraform state file to <dir>/<kind>/<id>.json, independently of the terraform state.
+*/
+func Backup(args []string) int {
+	flags := flag.NewFlagSet("backup", flag.ContinueOnError)
+	statePath := flags.String("state", "terraform.tfstate", "Path of the terraform state file listing the objects to back up")
+	dir := flags.String("dir", "signalform-backup", "Directory where the objects are written")
+	authToken := flags.String("auth-token", "", "SignalFx auth token. Looked up like the provider does if not set")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	config, err := getCommandConfig(*authToken)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 1
+	}
+
+	state, err := ioutil.ReadFile(*statePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to read state file %s: %s\n", *statePath, err.Error())
+		return 1
+	}
+	objects, err := getBackupObjects(state)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to parse state file %s: %s\n", *statePath, err.Error())
+		return 1
+	}
+
+	for _, object := range objects {
+		if err := backupObjectToDir(object, *dir, config); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return 1
+		}
+	}
+	fmt.Printf("Backed up %d objects to %s\n", len(objects), *dir)
+	return 0
+}
+
+/*
+  Looks up the auth token of commands like the provider does: the configuration files first, then the
+  environment and finally the command line flag.
+*/
+func getCommandConfig(flagToken string) (*signalformConfig, error) {
+	config := signalformConfig{}
+	if err := readConfigFiles(&config); err != nil {
+		return nil, err
+	}
+	if token := os.Getenv("SFX_AUTH_TOKEN"); token != "" {
+		config.AuthToken = token
+	}
+	if flagToken != "" {
+		config.AuthToken = flagToken
+	}
+	if config.AuthToken == "" {
+		return nil, fmt.Errorf("auth_token: required field is not set")
+	}
+	return &config, nil
+}
+
+/*
+  Lists the objects to back up from a terraform state, supporting both the version 3 (terraform < 0.12)
+  and version 4 formats. The result is sorted so that backups are reproducible.
+*/
+func getBackupObjects(state []byte) ([]backupObject, error) {
+	parsed := struct {
+		Version int `json:"version"`
+		// version 3
+		Modules []struct {
+			Resources map[string]struct {
+				Type    string `json:"type"`
+				Primary struct {
+					Id string `json:"id"`
+				} `json:"primary"`
+			} `json:"resources"`
+		} `json:"modules"`
+		// version 4
+		Resources []struct {
+			Mode      string `json:"mode"`
+			Type      string `json:"type"`
+			Instances []struct {
+				Attributes struct {
+					Id string `json:"id"`
+				} `json:"attributes"`
+			} `json:"instances"`
+		} `json:"resources"`
+	}{}
+	if err := json.Unmarshal(state, &parsed); err != nil {
+		return nil, err
+	}
+
+	objects := make([]backupObject, 0)
+	for _, module := range parsed.Modules {
+		for _, resource := range module.Resources {
+			if kind, ok := BackupResourceKinds[resource.Type]; ok && resource.Primary.Id != "" {
+				objects = append(objects, backupObject{Kind: kind, Id: resource.Primary.Id})
+			}
+		}
+	}
+	for _, resource := range parsed.Resources {
+		kind, ok := BackupResourceKinds[resource.Type]
+		if !ok || resource.Mode != "managed" {
+			continue
+		}
+		for _, instance := range resource.Instances {
+			if instance.Attributes.Id != "" {
+				objects = append(objects, backupObject{Kind: kind, Id: instance.Attributes.Id})
+			}
+		}
+	}
+
+	sort.Slice(objects, func(i, j int) bool {
+		if objects[i].Kind != objects[j].Kind {
+			return objects[i].Kind < objects[j].Kind
+		}
+		return objects[i].Id < objects[j].Id
+	})
+	return objects, nil
+}
+
+func backupObjectToDir(object backupObject, dir string, config *signalformConfig) error {
+	url := fmt.Sprintf("%s/%s", BackupKinds[object.Kind], object.Id)
+	status_code, resp_body, err := sendRequest("GET", url, config, nil)
+	if err != nil {
+		return err
+	}
+	if status_code != 200 {
+		return fmt.Errorf("For the %s %s SignalFx returned status %d: \n%s", object.Kind, object.Id, status_code, resp_body)
+	}
+
+	kindDir := filepath.Join(dir, object.Kind)
+	if err := os.MkdirAll(kindDir, 0755); err != nil {
+		return fmt.Errorf("Failed to create backup directory %s: %s", kindDir, err.Error())
+	}
+	path := filepath.Join(kindDir, object.Id+".json")
+	if err := ioutil.WriteFile(path, resp_body, 0644); err != nil {
+		return fmt.Errorf("Failed to write %s: %s", path, err.Error())
+	}
+	return nil
+}
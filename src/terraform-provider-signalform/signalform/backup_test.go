@@ -0,0 +1,51 @@
+package signalform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetBackupObjectsStateV4(t *testing.T) {
+	state := `{
+		"version": 4,
+		"resources": [
+			{"mode": "managed", "type": "signalform_time_chart", "name": "a", "instances": [{"attributes": {"id": "C2"}}, {"attributes": {"id": "C1"}}]},
+			{"mode": "managed", "type": "signalform_detector", "name": "b", "instances": [{"attributes": {"id": "D1"}}]},
+			{"mode": "managed", "type": "signalform_integration", "name": "c", "instances": [{"attributes": {"id": "I1"}}]},
+			{"mode": "data", "type": "signalform_dashboard", "name": "d", "instances": [{"attributes": {"id": "X1"}}]}
+		]
+	}`
+
+	objects, err := getBackupObjects([]byte(state))
+	assert.Nil(t, err)
+	assert.Equal(t, []backupObject{
+		backupObject{Kind: "chart", Id: "C1"},
+		backupObject{Kind: "chart", Id: "C2"},
+		backupObject{Kind: "detector", Id: "D1"},
+	}, objects)
+}
+
+func TestGetBackupObjectsStateV3(t *testing.T) {
+	state := `{
+		"version": 3,
+		"modules": [
+			{"resources": {
+				"signalform_dashboard.a": {"type": "signalform_dashboard", "primary": {"id": "B1"}},
+				"signalform_dashboard_group.b": {"type": "signalform_dashboard_group", "primary": {"id": "G1"}}
+			}}
+		]
+	}`
+
+	objects, err := getBackupObjects([]byte(state))
+	assert.Nil(t, err)
+	assert.Equal(t, []backupObject{
+		backupObject{Kind: "dashboard", Id: "B1"},
+		backupObject{Kind: "dashboardgroup", Id: "G1"},
+	}, objects)
+}
+
+func TestGetBackupObjectsInvalidState(t *testing.T) {
+	_, err := getBackupObjects([]byte(`{"version`))
+	assert.NotNil(t, err)
+}
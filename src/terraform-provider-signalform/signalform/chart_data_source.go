@@ -0,0 +1,89 @@
+package signalform
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+/*
+  Data source looking up a chart by name, optionally narrowed down by tags and creator, so that dashboards
+  can embed charts created manually or by other workspaces
+*/
+func chartDataSource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Name of the chart",
+			},
+			"tags": &schema.Schema{
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Tags the chart must have",
+			},
+			"creator": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "ID of the user who created the chart",
+			},
+			"description": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Description of the chart",
+			},
+			"program_text": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Signalflow program text of the chart",
+			},
+			"url": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "URL of the chart",
+			},
+		},
+
+		Read: chartDataSourceRead,
+	}
+}
+
+func chartDataSourceRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*signalformConfig)
+	name := d.Get("name").(string)
+	tags := make([]string, 0)
+	for _, tag := range d.Get("tags").([]interface{}) {
+		tags = append(tags, tag.(string))
+	}
+
+	charts, err := searchSignalFxObjects(CHART_API_URL, url.Values{"name": []string{name}}, config)
+	if err != nil {
+		return fmt.Errorf("Searching charts: %s", err.Error())
+	}
+	chart, err := findChart(charts, name, tags, d.Get("creator").(string))
+	if err != nil {
+		return err
+	}
+
+	id, _ := chart["id"].(string)
+	d.SetId(id)
+	d.Set("description", chart["description"])
+	d.Set("program_text", chart["programText"])
+	return d.Set("url", getResourceUrl(CHART_URL, id, config))
+}
+
+/*
+  Keeps the charts having exactly the name, all the tags and the creator if set, which must leave a single one
+*/
+func findChart(charts []map[string]interface{}, name string, tags []string, creator string) (map[string]interface{}, error) {
+	candidates := make([]map[string]interface{}, 0)
+	for _, chart := range filterObjectsByTags(charts, tags) {
+		if creator == "" || chart["creator"] == creator {
+			candidates = append(candidates, chart)
+		}
+	}
+	return findObjectByName("chart", candidates, name)
+}
@@ -0,0 +1,28 @@
+package signalform
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFindChart(t *testing.T) {
+	charts := []map[string]interface{}{
+		map[string]interface{}{"id": "C1", "name": "CPU", "creator": "alice", "tags": []interface{}{"infra"}},
+		map[string]interface{}{"id": "C2", "name": "CPU", "creator": "bob", "tags": []interface{}{"infra", "prod"}},
+		map[string]interface{}{"id": "C3", "name": "CPU per host", "creator": "bob"},
+	}
+
+	_, err := findChart(charts, "CPU", []string{}, "")
+	assert.Contains(t, err.Error(), "2 charts are named CPU")
+
+	chart, err := findChart(charts, "CPU", []string{"prod"}, "")
+	assert.Nil(t, err)
+	assert.Equal(t, "C2", chart["id"])
+
+	chart, err = findChart(charts, "CPU", []string{}, "alice")
+	assert.Nil(t, err)
+	assert.Equal(t, "C1", chart["id"])
+
+	_, err = findChart(charts, "CPU", []string{"prod"}, "alice")
+	assert.Contains(t, err.Error(), "No chart named CPU")
+}
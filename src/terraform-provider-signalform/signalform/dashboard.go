@@ -4,14 +4,20 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"sort"
 	"strings"
 
+	"github.com/hashicorp/terraform/configs/hcl2shim"
+	"github.com/hashicorp/terraform/helper/customdiff"
 	"github.com/hashicorp/terraform/helper/schema"
+	"terraform-provider-signalform/internal/sfxtime"
 )
 
 const (
 	DASHBOARD_API_URL = "https://api.signalfx.com/v2/dashboard"
 	DASHBOARD_URL     = "https://app.signalfx.com/#/dashboard/<id>"
+	// Maximum number of charts SignalFx accepts in a dashboard
+	DASHBOARD_MAX_CHARTS = 100
 )
 
 func dashboardResource() *schema.Resource {
@@ -28,6 +34,7 @@ func dashboardResource() *schema.Resource {
 				Computed:    true,
 				Description: "Latest timestamp the resource was updated",
 			},
+			"protect_from_deletion": protectFromDeletionSchema(),
 			"resource_url": &schema.Schema{
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -51,8 +58,29 @@ func dashboardResource() *schema.Resource {
 			},
 			"dashboard_group": &schema.Schema{
 				Type:        schema.TypeString,
-				Required:    true,
-				Description: "The ID of the dashboard group that contains the dashboard. If an ID is not provided during creation, the dashboard will be placed in a newly created dashboard group",
+				Optional:    true,
+				Description: "The ID of the dashboard group that contains the dashboard. Required unless the provider sets default_dashboard_group",
+			},
+			"layout": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "manual",
+				Description:  "How the charts listed in chart blocks are placed. \"manual\" (default) uses their row and column, \"auto\" packs them based on their width and height, in the order of the chart blocks, around the charts of grid and column blocks",
+				ValidateFunc: validateDashboardLayout,
+			},
+			"raw_json": &schema.Schema{
+				Type:          schema.TypeString,
+				Optional:      true,
+				StateFunc:     normalizeJsonState,
+				ValidateFunc:  validateDashboardRawJson,
+				Description:   "JSON of the dashboard as returned by the SignalFx API (e.g. written by the backup command), sent as is instead of the other attributes. Only name, description and dashboard_group are set on top of it. Dashboards exported from the SignalFx UI are not supported",
+				ConflictsWith: []string{"charts_resolution", "time_range", "start_time", "end_time", "chart", "grid", "column", "variable", "filter", "event_overlay", "selected_event_overlay", "permissions", "authorized_writer_users", "authorized_writer_teams", "charts_managed_externally"},
+			},
+			"charts_managed_externally": &schema.Schema{
+				Type:          schema.TypeBool,
+				Optional:      true,
+				Description:   "(false by default) Whether the charts are placed with signalform_dashboard_chart resources, in which case updates keep the charts of the dashboard instead of replacing them",
+				ConflictsWith: []string{"raw_json", "chart", "grid", "column"},
 			},
 			"charts_resolution": &schema.Schema{
 				Type:         schema.TypeString,
@@ -63,7 +91,7 @@ func dashboardResource() *schema.Resource {
 			"time_range": &schema.Schema{
 				Type:          schema.TypeString,
 				Optional:      true,
-				ValidateFunc:  validateSignalfxRelativeTime,
+				ValidateFunc:  sfxtime.Validate,
 				Description:   "From when to display data. SignalFx time syntax (e.g. -5m, -1h)",
 				ConflictsWith: []string{"start_time", "end_time"},
 			},
@@ -79,8 +107,19 @@ func dashboardResource() *schema.Resource {
 				Description:   "Seconds since epoch to end the visualization",
 				ConflictsWith: []string{"time_range"},
 			},
+			"mirror_count": &schema.Schema{
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Number of dashboard groups, other than dashboard_group, in which the dashboard is mirrored",
+			},
+			"mirror_group_ids": &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "IDs of the dashboard groups, other than dashboard_group, in which the dashboard is mirrored",
+			},
 			"chart": &schema.Schema{
-				Type:        schema.TypeSet,
+				Type:        schema.TypeList,
 				Optional:    true,
 				Description: "Chart ID and layout information for the charts in the dashboard",
 				Elem: &schema.Resource{
@@ -151,6 +190,30 @@ func dashboardResource() *schema.Resource {
 							Default:     1,
 							Description: "How many rows each chart should take up. (greater than or equal to 1)",
 						},
+						"chart_override": &schema.Schema{
+							Type:        schema.TypeList,
+							Optional:    true,
+							Description: "Width and height of a chart of the grid, instead of the ones of the grid",
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"chart_id": &schema.Schema{
+										Type:        schema.TypeString,
+										Required:    true,
+										Description: "ID of the chart, which must be in chart_ids",
+									},
+									"width": &schema.Schema{
+										Type:        schema.TypeInt,
+										Optional:    true,
+										Description: "How many columns (out of a total of 12) the chart should take up. (between 1 and 12). Width of the grid if not set",
+									},
+									"height": &schema.Schema{
+										Type:        schema.TypeInt,
+										Optional:    true,
+										Description: "How many rows the chart should take up. (greater than or equal to 1). Height of the grid if not set",
+									},
+								},
+							},
+						},
 					},
 				},
 			},
@@ -169,9 +232,15 @@ func dashboardResource() *schema.Resource {
 						"column": &schema.Schema{
 							Type:        schema.TypeInt,
 							Optional:    true,
-							Description: "Column number for the layout",
+							Description: "Column number for the layout. With auto_column, position of the column among the auto placed ones instead",
 							Default:     0,
 						},
+						"auto_column": &schema.Schema{
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "(false by default) Whether to place the column right after the auto placed columns with a lower column value, based on their widths",
+						},
 						"start_row": &schema.Schema{
 							Type:        schema.TypeInt,
 							Optional:    true,
@@ -193,9 +262,22 @@ func dashboardResource() *schema.Resource {
 					},
 				},
 			},
-			"variable": &schema.Schema{
+			"permissions": permissionsSchema("dashboard"),
+			"authorized_writer_users": &schema.Schema{
 				Type:        schema.TypeSet,
 				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "User IDs allowed to modify the dashboard. Everyone can if neither users nor teams are set",
+			},
+			"authorized_writer_teams": &schema.Schema{
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Team IDs allowed to modify the dashboard. Everyone can if neither users nor teams are set",
+			},
+			"variable": &schema.Schema{
+				Type:        schema.TypeList,
+				Optional:    true,
 				Description: "Dashboard variable to apply to each chart in the dashboard",
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
@@ -217,7 +299,7 @@ func dashboardResource() *schema.Resource {
 						"values": &schema.Schema{
 							Type:        schema.TypeSet,
 							Optional:    true,
-							Elem:        &schema.Schema{Type: schema.TypeString},
+							Elem:        &schema.Schema{Type: schema.TypeString, ValidateFunc: validateFilterValue},
 							Description: "List of strings (which will be treated as an OR filter on the property)",
 						},
 						"value_required": &schema.Schema{
@@ -273,7 +355,7 @@ func dashboardResource() *schema.Resource {
 						"values": &schema.Schema{
 							Type:        schema.TypeSet,
 							Required:    true,
-							Elem:        &schema.Schema{Type: schema.TypeString},
+							Elem:        &schema.Schema{Type: schema.TypeString, ValidateFunc: validateFilterValue},
 							Description: "List of strings (which will be treated as an OR filter on the property)",
 						},
 						"apply_if_exist": &schema.Schema{
@@ -310,8 +392,13 @@ func dashboardResource() *schema.Resource {
 						},
 						"signal": &schema.Schema{
 							Type:        schema.TypeString,
-							Required:    true,
-							Description: "Search term used to define events",
+							Optional:    true,
+							Description: "Search term used to define events. Required unless detector_id is set",
+						},
+						"detector_id": &schema.Schema{
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "ID of the detector whose events are shown. Requires type to be \"detectorEvents\"",
 						},
 						"type": &schema.Schema{
 							Type:         schema.TypeString,
@@ -356,8 +443,13 @@ func dashboardResource() *schema.Resource {
 					Schema: map[string]*schema.Schema{
 						"signal": &schema.Schema{
 							Type:        schema.TypeString,
-							Required:    true,
-							Description: "Search term used to define events",
+							Optional:    true,
+							Description: "Search term used to define events. Required unless detector_id is set",
+						},
+						"detector_id": &schema.Schema{
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "ID of the detector whose events are shown. Requires type to be \"detectorEvents\"",
 						},
 						"type": &schema.Schema{
 							Type:         schema.TypeString,
@@ -400,17 +492,41 @@ func dashboardResource() *schema.Resource {
 		Read:   dashboardRead,
 		Update: dashboardUpdate,
 		Delete: dashboardDelete,
+
+		SchemaVersion: 2,
+		MigrateState:  dashboardMigrateState,
+
+		CustomizeDiff: customdiff.All(
+			validateDashboardGroupSet,
+			validateDashboardChartCount,
+			validateDashboardGridOverrides,
+			validateDashboardAutoColumns,
+		),
 	}
 }
 
+/*
+  dashboard_group, or the default dashboard group of the provider when it is not set
+*/
+func getDashboardGroupId(d *schema.ResourceData, config *signalformConfig) string {
+	if group, ok := d.GetOk("dashboard_group"); ok {
+		return group.(string)
+	}
+	return config.DefaultDashboardGroup
+}
+
 /*
   Use Resource object to construct json payload in order to create a dashboard
 */
-func getPayloadDashboard(d *schema.ResourceData) ([]byte, error) {
+func getPayloadDashboard(d *schema.ResourceData, config *signalformConfig) ([]byte, error) {
+	if rawJson, ok := d.GetOk("raw_json"); ok {
+		return getPayloadDashboardRawJson(d, rawJson.(string), config)
+	}
+
 	payload := map[string]interface{}{
 		"name":        d.Get("name").(string),
 		"description": d.Get("description").(string),
-		"groupId":     d.Get("dashboard_group").(string),
+		"groupId":     getDashboardGroupId(d, config),
 	}
 
 	all_filters := make(map[string]interface{})
@@ -427,16 +543,26 @@ func getPayloadDashboard(d *schema.ResourceData) ([]byte, error) {
 		payload["filters"] = all_filters
 	}
 
-	overlays := d.Get("event_overlay").([]interface{})
-	payload["eventOverlays"] = getDashboardEventOverlays(overlays)
+	overlays, err := getDashboardEventOverlays(d.Get("event_overlay").([]interface{}))
+	if err != nil {
+		return nil, err
+	}
+	payload["eventOverlays"] = overlays
 
-	soverlays := d.Get("selected_event_overlay").([]interface{})
-	payload["selectedEventOverlays"] = getDashboardEventOverlays(soverlays)
+	soverlays, err := getDashboardEventOverlays(d.Get("selected_event_overlay").([]interface{}))
+	if err != nil {
+		return nil, err
+	}
+	payload["selectedEventOverlays"] = soverlays
 
 	charts := getDashboardCharts(d)
 	column_charts := getDashboardColumns(d)
-	dashboard_charts := append(charts, column_charts...)
 	grid_charts := getDashboardGrids(d)
+	if d.Get("layout").(string) == "auto" {
+		reserved := append(append([]map[string]interface{}{}, column_charts...), grid_charts...)
+		charts = packDashboardCharts(charts, reserved)
+	}
+	dashboard_charts := append(charts, column_charts...)
 	dashboard_charts = append(dashboard_charts, grid_charts...)
 	if len(dashboard_charts) > 0 {
 		payload["charts"] = dashboard_charts
@@ -445,9 +571,72 @@ func getPayloadDashboard(d *schema.ResourceData) ([]byte, error) {
 	if chartsResolution, ok := d.GetOk("charts_resolution"); ok {
 		payload["chartDensity"] = strings.ToUpper(chartsResolution.(string))
 	}
+	payload["permissions"] = getPermissions(d)
+	payload["authorizedWriters"] = getAuthorizedWriters(d)
+	return json.Marshal(payload)
+}
+
+/*
+  Use the raw JSON of the dashboard as payload, without the fields set by SignalFx
+*/
+func getPayloadDashboardRawJson(d *schema.ResourceData, rawJson string, config *signalformConfig) ([]byte, error) {
+	payload := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(rawJson), &payload); err != nil {
+		return nil, err
+	}
+	for _, field := range readOnlyFields {
+		delete(payload, field)
+	}
+	payload["name"] = d.Get("name").(string)
+	payload["groupId"] = getDashboardGroupId(d, config)
+	if description, ok := d.GetOk("description"); ok {
+		payload["description"] = description.(string)
+	}
 	return json.Marshal(payload)
 }
 
+/*
+  The export of the SignalFx UI wraps the dashboard, its charts and its group in a package of another format,
+  which the dashboard API doesn't accept
+*/
+func validateDashboardRawJson(v interface{}, k string) (we []string, errors []error) {
+	if we, errors = validateJsonObject(v, k); len(errors) > 0 {
+		return
+	}
+	value := map[string]interface{}{}
+	json.Unmarshal([]byte(v.(string)), &value)
+	for _, field := range []string{"packageType", "dashboardExport"} {
+		if _, ok := value[field]; ok {
+			errors = append(errors, fmt.Errorf("%s is a dashboard exported from the SignalFx UI, which is not supported; use the JSON of the SignalFx API instead, e.g. a file written by the backup command", k))
+			return
+		}
+	}
+	return
+}
+
+/*
+  Returns the normalized JSON of the fields of the dashboard which are set in rawJson, so that only
+  changes to these fields are reported as drift
+*/
+func getDashboardRawJson(dashboard map[string]interface{}, rawJson string) (string, error) {
+	configured := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(rawJson), &configured); err != nil {
+		return "", err
+	}
+	// Managed by their own attributes or set by SignalFx, these are kept as configured
+	ignored := map[string]bool{"name": true, "groupId": true, "description": true}
+	for _, field := range readOnlyFields {
+		ignored[field] = true
+	}
+	for field := range configured {
+		if !ignored[field] {
+			configured[field] = dashboard[field]
+		}
+	}
+	normalized, err := json.Marshal(configured)
+	return string(normalized), err
+}
+
 func getDashboardTime(d *schema.ResourceData) map[string]interface{} {
 	timeMap := make(map[string]interface{})
 	if val, ok := d.GetOk("time_range"); ok {
@@ -469,7 +658,7 @@ func getDashboardTime(d *schema.ResourceData) map[string]interface{} {
 }
 
 func getDashboardCharts(d *schema.ResourceData) []map[string]interface{} {
-	charts := d.Get("chart").(*schema.Set).List()
+	charts := d.Get("chart").([]interface{})
 	charts_list := make([]map[string]interface{}, len(charts))
 	for i, chart := range charts {
 		chart := chart.(map[string]interface{})
@@ -486,14 +675,85 @@ func getDashboardCharts(d *schema.ResourceData) []map[string]interface{} {
 	return charts_list
 }
 
+/*
+  Places the charts on the 12 columns of the dashboard in the order they are declared, each one at the
+  topmost then leftmost position where it fits. The cells of the reserved charts (e.g. the ones of the
+  grid and column blocks) are left untouched.
+*/
+func packDashboardCharts(charts []map[string]interface{}, reserved []map[string]interface{}) []map[string]interface{} {
+	occupied := make([][12]bool, 0)
+	occupy := func(row int, column int, width int, height int) {
+		for len(occupied) < row+height {
+			occupied = append(occupied, [12]bool{})
+		}
+		for r := row; r < row+height; r++ {
+			for c := column; c < column+width && c < 12; c++ {
+				occupied[r][c] = true
+			}
+		}
+	}
+	fits := func(row int, column int, width int, height int) bool {
+		for r := row; r < row+height && r < len(occupied); r++ {
+			for c := column; c < column+width; c++ {
+				if occupied[r][c] {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	for _, chart := range reserved {
+		row, column := chart["row"].(int), chart["column"].(int)
+		if row >= 0 && column >= 0 && column < 12 {
+			occupy(row, column, chart["width"].(int), chart["height"].(int))
+		}
+	}
+	for _, chart := range charts {
+		width := chart["width"].(int)
+		if width > 12 {
+			width = 12
+		} else if width < 1 {
+			width = 1
+		}
+		height := chart["height"].(int)
+		if height < 1 {
+			height = 1
+		}
+
+		row, column := 0, 0
+		for ; ; row++ {
+			found := false
+			for column = 0; column+width <= 12; column++ {
+				if fits(row, column, width, height) {
+					found = true
+					break
+				}
+			}
+			if found {
+				break
+			}
+		}
+		occupy(row, column, width, height)
+		chart["row"] = row
+		chart["column"] = column
+		chart["width"] = width
+		chart["height"] = height
+	}
+	return charts
+}
+
 func getDashboardColumns(d *schema.ResourceData) []map[string]interface{} {
 	columns := d.Get("column").(*schema.Set).List()
+	auto_columns := getAutoColumnNumbers(columns)
 	charts := make([]map[string]interface{}, 0)
-	for _, column := range columns {
+	for i, column := range columns {
 		column := column.(map[string]interface{})
 
 		current_row := column["start_row"].(int)
 		column_number := column["column"].(int)
+		if val, ok := auto_columns[i]; ok {
+			column_number = val
+		}
 		width := column["width"].(int)
 		height := column["height"].(int)
 		for _, chart_id := range column["chart_ids"].([]interface{}) {
@@ -512,39 +772,97 @@ func getDashboardColumns(d *schema.ResourceData) []map[string]interface{} {
 	return charts
 }
 
+/*
+  Computes the column numbers of the auto placed columns, indexed like columns: they are packed from the
+  left, ordered by their column value, each starting where the previous one ends.
+*/
+func getAutoColumnNumbers(columns []interface{}) map[int]int {
+	indexes := make([]int, 0)
+	for i, column := range columns {
+		if auto, ok := column.(map[string]interface{})["auto_column"].(bool); ok && auto {
+			indexes = append(indexes, i)
+		}
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return columns[indexes[i]].(map[string]interface{})["column"].(int) < columns[indexes[j]].(map[string]interface{})["column"].(int)
+	})
+
+	column_numbers := make(map[int]int)
+	current_column := 0
+	for _, index := range indexes {
+		column_numbers[index] = current_column
+		current_column += columns[index].(map[string]interface{})["width"].(int)
+	}
+	return column_numbers
+}
+
 func getDashboardGrids(d *schema.ResourceData) []map[string]interface{} {
 	grids := d.Get("grid").(*schema.Set).List()
 	charts := make([]map[string]interface{}, 0)
 	for _, grid := range grids {
-		grid := grid.(map[string]interface{})
+		charts = append(charts, getGridCharts(grid.(map[string]interface{}))...)
+	}
+	return charts
+}
 
-		current_row := grid["start_row"].(int)
-		current_column := grid["start_column"].(int)
-		width := grid["width"].(int)
-		height := grid["height"].(int)
-		for _, chart_id := range grid["chart_ids"].([]interface{}) {
-			item := make(map[string]interface{})
+/*
+  Places the charts of a grid row by row. Rows advance by 1 like they always did, unless a chart of the
+  current row has an overridden height: the next row then starts below the tallest overridden chart.
+*/
+func getGridCharts(grid map[string]interface{}) []map[string]interface{} {
+	overrides := make(map[string]map[string]interface{})
+	if tf_overrides, ok := grid["chart_override"].([]interface{}); ok {
+		for _, override := range tf_overrides {
+			override := override.(map[string]interface{})
+			overrides[override["chart_id"].(string)] = override
+		}
+	}
 
-			item["chartId"] = chart_id.(string)
-			item["height"] = height
-			item["width"] = width
+	charts := make([]map[string]interface{}, 0)
+	current_row := grid["start_row"].(int)
+	current_column := grid["start_column"].(int)
+	row_charts := 0
+	row_step := 1
+	for _, chart_id := range grid["chart_ids"].([]interface{}) {
+		item := make(map[string]interface{})
 
-			if current_column+width > 12 {
-				current_row += 1
-				current_column = grid["start_column"].(int)
+		width := grid["width"].(int)
+		height := grid["height"].(int)
+		height_override := 0
+		if override, ok := overrides[chart_id.(string)]; ok {
+			if val, ok := override["width"].(int); ok && val > 0 {
+				width = val
 			}
-			item["row"] = current_row
-			item["column"] = current_column
+			if val, ok := override["height"].(int); ok && val > 0 {
+				height = val
+				height_override = val
+			}
+		}
+		item["chartId"] = chart_id.(string)
+		item["height"] = height
+		item["width"] = width
 
-			current_column += width
-			charts = append(charts, item)
+		if current_column+width > 12 && row_charts > 0 {
+			current_row += row_step
+			current_column = grid["start_column"].(int)
+			row_charts = 0
+			row_step = 1
+		}
+		item["row"] = current_row
+		item["column"] = current_column
+
+		current_column += width
+		row_charts++
+		if height_override > row_step {
+			row_step = height_override
 		}
+		charts = append(charts, item)
 	}
 	return charts
 }
 
 func getDashboardVariables(d *schema.ResourceData) []map[string]interface{} {
-	variables := d.Get("variable").(*schema.Set).List()
+	variables := d.Get("variable").([]interface{})
 	vars_list := make([]map[string]interface{}, len(variables))
 	for i, variable := range variables {
 		variable := variable.(map[string]interface{})
@@ -580,15 +898,24 @@ func getDashboardVariables(d *schema.ResourceData) []map[string]interface{} {
 	return vars_list
 }
 
-func getDashboardEventOverlays(overlays []interface{}) []map[string]interface{} {
+func getDashboardEventOverlays(overlays []interface{}) ([]map[string]interface{}, error) {
 	overlay_list := make([]map[string]interface{}, len(overlays))
 	for i, overlay := range overlays {
 		overlay := overlay.(map[string]interface{})
 		item := make(map[string]interface{})
-		item["eventSignal"] = map[string]interface{}{
+		eventSignal := map[string]interface{}{
 			"eventSearchText": overlay["signal"].(string),
 			"eventType":       overlay["type"].(string),
 		}
+		if detectorId, ok := overlay["detector_id"].(string); ok && detectorId != "" {
+			if overlay["type"].(string) != "detectorEvents" {
+				return nil, fmt.Errorf("Event overlay with detector_id %s must have type \"detectorEvents\"", detectorId)
+			}
+			eventSignal["detectorId"] = detectorId
+		} else if overlay["signal"].(string) == "" {
+			return nil, fmt.Errorf("Event overlay must have a signal or a detector_id")
+		}
+		item["eventSignal"] = eventSignal
 		if val, ok := overlay["line"].(bool); ok {
 			item["eventLine"] = val
 		}
@@ -617,7 +944,7 @@ func getDashboardEventOverlays(overlays []interface{}) []map[string]interface{}
 
 		overlay_list[i] = item
 	}
-	return overlay_list
+	return overlay_list, nil
 }
 
 func getDashboardFilters(d *schema.ResourceData) []map[string]interface{} {
@@ -639,36 +966,234 @@ func getDashboardFilters(d *schema.ResourceData) []map[string]interface{} {
 
 func dashboardCreate(d *schema.ResourceData, meta interface{}) error {
 	config := meta.(*signalformConfig)
-	payload, err := getPayloadDashboard(d)
+	payload, err := getPayloadDashboard(d, config)
 	if err != nil {
 		return fmt.Errorf("Failed creating json payload: %s", err.Error())
 	}
 	log.Printf("[SignalForm] Dashboard Create Payload: %s", string(payload))
-	return resourceCreate(DASHBOARD_API_URL, config.AuthToken, payload, d)
+	return resourceCreate(DASHBOARD_API_URL, config, payload, d)
 }
 
 func dashboardRead(d *schema.ResourceData, meta interface{}) error {
 	config := meta.(*signalformConfig)
 	url := fmt.Sprintf("%s/%s", DASHBOARD_API_URL, d.Id())
 
-	return resourceRead(url, config.AuthToken, d)
+	return resourceReadWithState(url, config, d, func(dashboard map[string]interface{}, d *schema.ResourceData) error {
+		configGroupIds, err := getDashboardConfigGroupIdsCached(config)
+		if err != nil {
+			return err
+		}
+		// The structured attributes conflict with raw_json, which already reports the drift of the fields it sets
+		if rawJson, ok := d.GetOk("raw_json"); ok {
+			normalized, err := getDashboardRawJson(dashboard, rawJson.(string))
+			if err != nil {
+				return err
+			}
+			d.Set("raw_json", normalized)
+		} else {
+			// Leaving the resolution unset is the same as the default one, which is not worth a diff
+			chartsResolution := getChartsResolution(dashboard["chartDensity"])
+			if chartsResolution != "default" || d.Get("charts_resolution").(string) != "" {
+				d.Set("charts_resolution", chartsResolution)
+			}
+			if err := permissionsAPIToState(dashboard, d); err != nil {
+				return err
+			}
+		}
+
+		// The owning group comes from the API, as dashboard_group is not set yet when importing
+		groupId, _ := dashboard["groupId"].(string)
+		mirrorGroupIds := getDashboardMirrorGroupIds(configGroupIds[d.Id()], groupId)
+		d.Set("mirror_count", len(mirrorGroupIds))
+		return d.Set("mirror_group_ids", mirrorGroupIds)
+	})
+}
+
+/*
+  dashboard_group is only optional when the provider sets a default dashboard group
+*/
+func validateDashboardGroupSet(diff *schema.ResourceDiff, meta interface{}) error {
+	if config, ok := meta.(*signalformConfig); ok && config.DefaultDashboardGroup != "" {
+		return nil
+	}
+	if diff.NewValueKnown("dashboard_group") && diff.Get("dashboard_group").(string) == "" {
+		return fmt.Errorf("Dashboard %s: dashboard_group is required unless the provider sets default_dashboard_group", diff.Get("name"))
+	}
+	return nil
+}
+
+/*
+  Fails the plan of dashboards with more charts than SignalFx accepts, instead of failing in the middle of the apply
+*/
+func validateDashboardChartCount(diff *schema.ResourceDiff, meta interface{}) error {
+	count := countDashboardCharts(diff.Get)
+	if count > DASHBOARD_MAX_CHARTS {
+		return fmt.Errorf("Dashboard %s has %d charts, SignalFx accepts at most %d", diff.Get("name"), count, DASHBOARD_MAX_CHARTS)
+	}
+	if count > DASHBOARD_MAX_CHARTS*9/10 {
+		log.Printf("[WARN] Dashboard %s has %d charts, close to the limit of %d", diff.Get("name"), count, DASHBOARD_MAX_CHARTS)
+	}
+	return nil
+}
+
+/*
+  An override of a chart which is not in the grid would be silently ignored
+*/
+func validateDashboardGridOverrides(diff *schema.ResourceDiff, meta interface{}) error {
+	if !diff.NewValueKnown("grid") {
+		return nil
+	}
+	for _, grid := range diff.Get("grid").(*schema.Set).List() {
+		if err := checkGridOverrides(grid.(map[string]interface{})); err != nil {
+			return fmt.Errorf("Dashboard %s: %s", diff.Get("name"), err.Error())
+		}
+	}
+	return nil
+}
+
+func checkGridOverrides(grid map[string]interface{}) error {
+	chartIds := make(map[string]bool)
+	for _, chartId := range grid["chart_ids"].([]interface{}) {
+		chartId, _ := chartId.(string)
+		if strings.Contains(chartId, hcl2shim.UnknownVariableValue) {
+			return nil
+		}
+		chartIds[chartId] = true
+	}
+	overrides, _ := grid["chart_override"].([]interface{})
+	for _, override := range overrides {
+		chartId, _ := override.(map[string]interface{})["chart_id"].(string)
+		if !chartIds[chartId] && !strings.Contains(chartId, hcl2shim.UnknownVariableValue) {
+			return fmt.Errorf("chart_override of chart %s, which is not in the chart_ids of its grid", chartId)
+		}
+	}
+	return nil
+}
+
+/*
+  Auto placed columns which don't fit in the 12 columns of the dashboard would overflow it
+*/
+func validateDashboardAutoColumns(diff *schema.ResourceDiff, meta interface{}) error {
+	if !diff.NewValueKnown("column") {
+		return nil
+	}
+	if err := checkAutoColumns(diff.Get("column").(*schema.Set).List()); err != nil {
+		return fmt.Errorf("Dashboard %s: %s", diff.Get("name"), err.Error())
+	}
+	return nil
+}
+
+func checkAutoColumns(columns []interface{}) error {
+	total_width := 0
+	for index, column_number := range getAutoColumnNumbers(columns) {
+		if end := column_number + columns[index].(map[string]interface{})["width"].(int); end > total_width {
+			total_width = end
+		}
+	}
+	if total_width > 12 {
+		return fmt.Errorf("the auto placed columns are %d columns wide, a dashboard has 12", total_width)
+	}
+	return nil
+}
+
+/*
+  Counts the charts of the chart, grid and column blocks and of the raw JSON of a dashboard
+*/
+func countDashboardCharts(get func(string) interface{}) int {
+	count := len(get("chart").([]interface{}))
+	for _, layout := range []string{"grid", "column"} {
+		for _, block := range get(layout).(*schema.Set).List() {
+			count += len(block.(map[string]interface{})["chart_ids"].([]interface{}))
+		}
+	}
+	dashboard := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(get("raw_json").(string)), &dashboard); err == nil {
+		if charts, ok := dashboard["charts"].([]interface{}); ok {
+			count += len(charts)
+		}
+	}
+	return count
+}
+
+/*
+  Maps the chartDensity of the API (e.g. "HIGHEST", or null when never set) back to the values of charts_resolution
+*/
+func getChartsResolution(chartDensity interface{}) string {
+	if value, ok := chartDensity.(string); ok && value != "" {
+		return strings.ToLower(value)
+	}
+	return "default"
+}
+
+/*
+  Returns the IDs of the dashboard groups mirroring the dashboard, i.e. referencing it in their
+  dashboardConfigs while not being the group that owns it.
+*/
+func getDashboardMirrorGroupIds(configGroupIds []string, groupId string) []string {
+	mirrorGroupIds := make([]string, 0)
+	for _, configGroupId := range configGroupIds {
+		if configGroupId != groupId {
+			mirrorGroupIds = append(mirrorGroupIds, configGroupId)
+		}
+	}
+	return mirrorGroupIds
 }
 
 func dashboardUpdate(d *schema.ResourceData, meta interface{}) error {
 	config := meta.(*signalformConfig)
-	payload, err := getPayloadDashboard(d)
+	url := fmt.Sprintf("%s/%s", DASHBOARD_API_URL, d.Id())
+	payload, err := getPayloadDashboardUpdate(d, url, config)
 	if err != nil {
-		return fmt.Errorf("Failed creating json payload: %s", err.Error())
+		return err
 	}
-	url := fmt.Sprintf("%s/%s", DASHBOARD_API_URL, d.Id())
 	log.Printf("[SignalForm] Dashboard Update Payload: %s", string(payload))
-	return resourceUpdate(url, config.AuthToken, payload, d)
+	return resourceUpdate(url, config, payload, d)
+}
+
+/*
+  The configuration is authoritative: removing the last chart block removes the charts of the dashboard,
+  unless they are placed with signalform_dashboard_chart resources
+*/
+func getPayloadDashboardUpdate(d *schema.ResourceData, url string, config *signalformConfig) ([]byte, error) {
+	payload, err := getPayloadDashboard(d, config)
+	if err != nil {
+		return nil, fmt.Errorf("Failed creating json payload: %s", err.Error())
+	}
+	if d.Get("charts_managed_externally").(bool) {
+		return keepDashboardCharts(payload, url, config)
+	}
+	return payload, nil
+}
+
+/*
+  Sends the charts of the dashboard back as they are, for dashboards whose charts are managed with
+  signalform_dashboard_chart resources
+*/
+func keepDashboardCharts(payload []byte, url string, config *signalformConfig) ([]byte, error) {
+	decoded := map[string]interface{}{}
+	if err := json.Unmarshal(payload, &decoded); err != nil {
+		return nil, fmt.Errorf("Failed creating json payload: %s", err.Error())
+	}
+	dashboard, err := getSignalFxObject(url, config)
+	if err != nil {
+		return nil, fmt.Errorf("dashboard %s: %s", url, err.Error())
+	}
+	if charts, ok := dashboard["charts"]; ok {
+		decoded["charts"] = charts
+	}
+	return json.Marshal(decoded)
 }
 
 func dashboardDelete(d *schema.ResourceData, meta interface{}) error {
+	if err := checkDeletionProtection(d); err != nil {
+		return err
+	}
 	config := meta.(*signalformConfig)
 	url := fmt.Sprintf("%s/%s", DASHBOARD_API_URL, d.Id())
-	return resourceDelete(url, config.AuthToken, d)
+	if mirrors := d.Get("mirror_count").(int); mirrors > 0 {
+		log.Printf("[WARN] Deleting dashboard %s which is mirrored in %d other dashboard groups: %v", d.Get("name"), mirrors, d.Get("mirror_group_ids"))
+	}
+	return resourceDelete(url, config, d)
 }
 
 /*
@@ -686,6 +1211,18 @@ func validateChartsResolution(v interface{}, k string) (we []string, errors []er
 	return
 }
 
+func validateDashboardLayout(v interface{}, k string) (we []string, errors []error) {
+	value := v.(string)
+	allowedWords := []string{"manual", "auto"}
+	for _, word := range allowedWords {
+		if value == word {
+			return
+		}
+	}
+	errors = append(errors, fmt.Errorf("%s not allowed; must be one of: %s", value, strings.Join(allowedWords, ", ")))
+	return
+}
+
 func validateEventOverlayType(v interface{}, k string) (we []string, errors []error) {
 	value := v.(string)
 	allowedWords := []string{"eventTimeSeries", "detectorEvents"}
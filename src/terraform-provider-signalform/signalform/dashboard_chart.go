@@ -0,0 +1,197 @@
+package signalform
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+/*
+  Placements are stored in the charts of the dashboard, which are read, modified and written back as a
+  whole: modifications of the same dashboard must not interleave.
+*/
+var dashboardChartLock sync.Mutex
+
+func dashboardChartResource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"dashboard": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "ID of the dashboard to add the chart to",
+			},
+			"chart_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "ID of the chart to display",
+			},
+			"row": &schema.Schema{
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Description: "The row to show the chart in (zero-based); if height > 1, this value represents the topmost row of the chart. (greater than or equal to 0)",
+			},
+			"column": &schema.Schema{
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Description: "The column to show the chart in (zero-based); this value always represents the leftmost column of the chart. (between 0 and 11)",
+			},
+			"width": &schema.Schema{
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     12,
+				Description: "How many columns (out of a total of 12) the chart should take up. (between 1 and 12)",
+			},
+			"height": &schema.Schema{
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     1,
+				Description: "How many rows the chart should take up. (greater than or equal to 1)",
+			},
+		},
+
+		Create: dashboardchartCreate,
+		Read:   dashboardchartRead,
+		Update: dashboardchartUpdate,
+		Delete: dashboardchartDelete,
+		Importer: &schema.ResourceImporter{
+			State: dashboardchartImport,
+		},
+
+		CustomizeDiff: validateDashboardChartNotPlaced,
+	}
+}
+
+/*
+  Use Resource object to construct the charts entry of the placement
+*/
+func getDashboardChartPlacement(d *schema.ResourceData) map[string]interface{} {
+	return map[string]interface{}{
+		"chartId": d.Get("chart_id").(string),
+		"row":     d.Get("row").(int),
+		"column":  d.Get("column").(int),
+		"width":   d.Get("width").(int),
+		"height":  d.Get("height").(int),
+	}
+}
+
+/*
+  Applies modify to the charts of a dashboard and writes the dashboard back
+*/
+func updateDashboardCharts(dashboardId string, config *signalformConfig, modify func([]interface{}) ([]interface{}, error)) error {
+	url := fmt.Sprintf("%s/%s", DASHBOARD_API_URL, dashboardId)
+	return updateObjectEntries(&dashboardChartLock, url, "dashboard "+dashboardId, config, "charts", modify)
+}
+
+/*
+  A chart already placed on the dashboard, e.g. by another signalform_dashboard_chart or in the UI, would
+  only fail the apply
+*/
+func validateDashboardChartNotPlaced(diff *schema.ResourceDiff, meta interface{}) error {
+	if diff.Id() != "" || !diff.NewValueKnown("dashboard") || !diff.NewValueKnown("chart_id") {
+		return nil
+	}
+	config, ok := meta.(*signalformConfig)
+	if !ok {
+		return nil
+	}
+	dashboardId := diff.Get("dashboard").(string)
+	chartId := diff.Get("chart_id").(string)
+	url := fmt.Sprintf("%s/%s", DASHBOARD_API_URL, dashboardId)
+	placement, err := readObjectEntry(url, "dashboard "+dashboardId, config, "charts", "chartId", chartId)
+	if err != nil {
+		return err
+	}
+	if placement != nil {
+		return fmt.Errorf("Chart %s is already in the dashboard %s", chartId, dashboardId)
+	}
+	return nil
+}
+
+func dashboardchartCreate(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*signalformConfig)
+	dashboardId := d.Get("dashboard").(string)
+	chartId := d.Get("chart_id").(string)
+
+	err := updateDashboardCharts(dashboardId, config, func(charts []interface{}) ([]interface{}, error) {
+		if findObjectEntry(charts, "chartId", chartId) != -1 {
+			return nil, fmt.Errorf("Chart %s is already in the dashboard %s", chartId, dashboardId)
+		}
+		return append(charts, getDashboardChartPlacement(d)), nil
+	})
+	if err != nil {
+		return err
+	}
+	d.SetId(fmt.Sprintf("%s/%s", dashboardId, chartId))
+	return dashboardchartRead(d, meta)
+}
+
+func dashboardchartRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*signalformConfig)
+	dashboardId := d.Get("dashboard").(string)
+	url := fmt.Sprintf("%s/%s", DASHBOARD_API_URL, dashboardId)
+	chart, err := readObjectEntry(url, "dashboard "+dashboardId, config, "charts", "chartId", d.Get("chart_id").(string))
+	if err != nil {
+		return err
+	}
+	if chart == nil {
+		d.SetId("")
+		return nil
+	}
+	for _, field := range []string{"row", "column", "width", "height"} {
+		if val, ok := chart[field].(float64); ok {
+			d.Set(field, int(val))
+		}
+	}
+	return nil
+}
+
+func dashboardchartUpdate(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*signalformConfig)
+	chartId := d.Get("chart_id").(string)
+
+	err := updateDashboardCharts(d.Get("dashboard").(string), config, func(charts []interface{}) ([]interface{}, error) {
+		index := findObjectEntry(charts, "chartId", chartId)
+		if index == -1 {
+			return append(charts, getDashboardChartPlacement(d)), nil
+		}
+		charts[index] = getDashboardChartPlacement(d)
+		return charts, nil
+	})
+	if err != nil {
+		return err
+	}
+	return dashboardchartRead(d, meta)
+}
+
+func dashboardchartDelete(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*signalformConfig)
+	chartId := d.Get("chart_id").(string)
+
+	err := updateDashboardCharts(d.Get("dashboard").(string), config, func(charts []interface{}) ([]interface{}, error) {
+		if index := findObjectEntry(charts, "chartId", chartId); index != -1 {
+			charts = append(charts[:index], charts[index+1:]...)
+		}
+		return charts, nil
+	})
+	if err != nil {
+		return err
+	}
+	d.SetId("")
+	return nil
+}
+
+/*
+  Placements are imported with an ID of the form <dashboard ID>/<chart ID>
+*/
+func dashboardchartImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	dashboardId, chartId, err := parseObjectEntryId(d.Id(), "<dashboard ID>/<chart ID>")
+	if err != nil {
+		return nil, err
+	}
+	d.Set("dashboard", dashboardId)
+	d.Set("chart_id", chartId)
+	return []*schema.ResourceData{d}, nil
+}
@@ -0,0 +1,36 @@
+package signalform
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGetDashboardChartPlacement(t *testing.T) {
+	d := dashboardChartResource().TestResourceData()
+	d.Set("dashboard", "dash")
+	d.Set("chart_id", "chart")
+	d.Set("row", 2)
+	d.Set("width", 6)
+	d.Set("height", 1)
+
+	assert.Equal(t, map[string]interface{}{
+		"chartId": "chart",
+		"row":     2,
+		"column":  0,
+		"width":   6,
+		"height":  1,
+	}, getDashboardChartPlacement(d))
+}
+
+func TestDashboardChartImport(t *testing.T) {
+	d := dashboardChartResource().TestResourceData()
+	d.SetId("dash/chart")
+	_, err := dashboardchartImport(d, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "dash", d.Get("dashboard"))
+	assert.Equal(t, "chart", d.Get("chart_id"))
+
+	d.SetId("dash/")
+	_, err = dashboardchartImport(d, nil)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,122 @@
+package signalform
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+/*
+  Data source looking up a dashboard by ID, or by name and optionally dashboard group, so that
+  configurations can link to dashboards they do not manage
+*/
+func dashboardDataSource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"dashboard_id": &schema.Schema{
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				ConflictsWith: []string{"name"},
+				Description:   "ID of the dashboard. Required unless name is set",
+			},
+			"name": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "Name of the dashboard. It must match exactly one dashboard, of dashboard_group if set",
+			},
+			"dashboard_group": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "ID of the dashboard group of the dashboard",
+			},
+			"description": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Description of the dashboard",
+			},
+			"url": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "URL of the dashboard",
+			},
+			"charts": &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "IDs of the charts of the dashboard",
+			},
+		},
+
+		Read: dashboardDataSourceRead,
+	}
+}
+
+func dashboardDataSourceRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*signalformConfig)
+
+	var dashboard map[string]interface{}
+	if id, ok := d.GetOk("dashboard_id"); ok {
+		var err error
+		dashboard, err = getSignalFxObject(fmt.Sprintf("%s/%s", DASHBOARD_API_URL, id), config)
+		if err != nil {
+			return fmt.Errorf("Reading dashboard %s: %s", id, err.Error())
+		}
+	} else if name, ok := d.GetOk("name"); ok {
+		dashboards, err := searchSignalFxObjects(DASHBOARD_API_URL, url.Values{"name": []string{name.(string)}}, config)
+		if err != nil {
+			return fmt.Errorf("Searching dashboards: %s", err.Error())
+		}
+		dashboard, err = findDashboardByName(dashboards, name.(string), d.Get("dashboard_group").(string))
+		if err != nil {
+			return err
+		}
+	} else {
+		return fmt.Errorf("One of dashboard_id or name is required")
+	}
+
+	id, _ := dashboard["id"].(string)
+	d.SetId(id)
+	d.Set("dashboard_id", id)
+	d.Set("name", dashboard["name"])
+	d.Set("dashboard_group", dashboard["groupId"])
+	d.Set("description", dashboard["description"])
+	d.Set("url", getResourceUrl(DASHBOARD_URL, id, config))
+	return d.Set("charts", getDashboardChartIds(dashboard))
+}
+
+/*
+  Dashboard names are only unique within their group, which can be given to tell them apart
+*/
+func findDashboardByName(dashboards []map[string]interface{}, name string, groupId string) (map[string]interface{}, error) {
+	if groupId == "" {
+		return findObjectByName("dashboard", dashboards, name)
+	}
+	inGroup := make([]map[string]interface{}, 0)
+	for _, dashboard := range dashboards {
+		if dashboard["groupId"] == groupId {
+			inGroup = append(inGroup, dashboard)
+		}
+	}
+	dashboard, err := findObjectByName("dashboard", inGroup, name)
+	if err != nil {
+		return nil, fmt.Errorf("%s in dashboard group %s", err.Error(), groupId)
+	}
+	return dashboard, nil
+}
+
+func getDashboardChartIds(dashboard map[string]interface{}) []interface{} {
+	charts, _ := dashboard["charts"].([]interface{})
+	ids := make([]interface{}, 0, len(charts))
+	for _, chart := range charts {
+		if chart, ok := chart.(map[string]interface{}); ok {
+			if id, ok := chart["chartId"].(string); ok {
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
@@ -0,0 +1,34 @@
+package signalform
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFindDashboardByName(t *testing.T) {
+	dashboards := []map[string]interface{}{
+		map[string]interface{}{"id": "first", "name": "Latency", "groupId": "web"},
+		map[string]interface{}{"id": "second", "name": "Latency", "groupId": "database"},
+		map[string]interface{}{"id": "third", "name": "Latency per host", "groupId": "web"},
+	}
+	_, err := findDashboardByName(dashboards, "Latency", "")
+	assert.Contains(t, err.Error(), "2 dashboards are named Latency")
+
+	dashboard, err := findDashboardByName(dashboards, "Latency", "database")
+	assert.Nil(t, err)
+	assert.Equal(t, "second", dashboard["id"])
+
+	_, err = findDashboardByName(dashboards, "Latency", "cache")
+	assert.Contains(t, err.Error(), "No dashboard named Latency in dashboard group cache")
+}
+
+func TestGetDashboardChartIds(t *testing.T) {
+	dashboard := map[string]interface{}{
+		"charts": []interface{}{
+			map[string]interface{}{"chartId": "C1", "row": 0.0, "column": 0.0},
+			map[string]interface{}{"chartId": "C2", "row": 0.0, "column": 6.0},
+		},
+	}
+	assert.Equal(t, []interface{}{"C1", "C2"}, getDashboardChartIds(dashboard))
+	assert.Equal(t, []interface{}{}, getDashboardChartIds(map[string]interface{}{}))
+}
@@ -3,6 +3,7 @@ package signalform
 import (
 	"encoding/json"
 	"fmt"
+	"reflect"
 
 	"github.com/hashicorp/terraform/helper/schema"
 )
@@ -39,6 +40,7 @@ func dashboardGroupResource() *schema.Resource {
 				Elem:        &schema.Schema{Type: schema.TypeString},
 				Description: "Team IDs to associate the dashboard group to",
 			},
+			"permissions": permissionsSchema("dashboard group and its dashboards"),
 		},
 
 		Create: dashboardgroupCreate,
@@ -59,13 +61,80 @@ func getPayloadDashboardGroup(d *schema.ResourceData) ([]byte, error) {
 		"dashboards": make([]string, 0),
 	}
 
+	// Always sent, so that removing the last team from the configuration removes the association
+	payload["teams"] = make([]interface{}, 0)
 	if val, ok := d.GetOk("teams"); ok {
 		payload["teams"] = val.([]interface{})
 	}
+	payload["permissions"] = getPermissions(d)
 
 	return json.Marshal(payload)
 }
 
+/*
+  Lists every dashboard group in the organization, following the API pagination
+*/
+func listDashboardGroups(config *signalformConfig) ([]map[string]interface{}, error) {
+	groups := make([]map[string]interface{}, 0)
+	limit := 100
+	for offset := 0; ; offset += limit {
+		url := fmt.Sprintf("%s?limit=%d&offset=%d", DASHBOARD_GROUP_API_URL, limit, offset)
+		status_code, resp_body, err := sendRequest("GET", url, config, nil)
+		if err != nil {
+			return nil, err
+		}
+		if status_code != 200 {
+			return nil, fmt.Errorf("Listing dashboard groups SignalFx returned status %d: \n%s", status_code, resp_body)
+		}
+		page := struct {
+			Count   int                      `json:"count"`
+			Results []map[string]interface{} `json:"results"`
+		}{}
+		if err := json.Unmarshal(resp_body, &page); err != nil {
+			return nil, fmt.Errorf("Failed unmarshaling dashboard groups: %s", err.Error())
+		}
+		groups = append(groups, page.Results...)
+		if len(page.Results) < limit || len(groups) >= page.Count {
+			return groups, nil
+		}
+	}
+}
+
+/*
+  Returns, for each dashboard, the IDs of the dashboard groups listing it in their dashboardConfigs. The
+  groups are listed at most once per provider run, not on the refresh of every dashboard.
+*/
+func getDashboardConfigGroupIdsCached(config *signalformConfig) (map[string][]string, error) {
+	config.dashboardGroupsLock.Lock()
+	defer config.dashboardGroupsLock.Unlock()
+	if config.dashboardConfigGroupIds == nil {
+		groups, err := listDashboardGroups(config)
+		if err != nil {
+			return nil, err
+		}
+		config.dashboardConfigGroupIds = getDashboardConfigGroupIds(groups)
+	}
+	return config.dashboardConfigGroupIds, nil
+}
+
+func getDashboardConfigGroupIds(groups []map[string]interface{}) map[string][]string {
+	groupIds := make(map[string][]string)
+	for _, group := range groups {
+		groupId, _ := group["id"].(string)
+		seen := make(map[string]bool)
+		configs, _ := group["dashboardConfigs"].([]interface{})
+		for _, config := range configs {
+			if config, ok := config.(map[string]interface{}); ok {
+				if dashboardId, ok := config["dashboardId"].(string); ok && !seen[dashboardId] {
+					seen[dashboardId] = true
+					groupIds[dashboardId] = append(groupIds[dashboardId], groupId)
+				}
+			}
+		}
+	}
+	return groupIds
+}
+
 func dashboardgroupCreate(d *schema.ResourceData, meta interface{}) error {
 	config := meta.(*signalformConfig)
 	payload, err := getPayloadDashboardGroup(d)
@@ -73,14 +142,30 @@ func dashboardgroupCreate(d *schema.ResourceData, meta interface{}) error {
 		return fmt.Errorf("Failed creating json payload: %s", err.Error())
 	}
 
-	return resourceCreate(DASHBOARD_GROUP_API_URL, config.AuthToken, payload, d)
+	return resourceCreate(DASHBOARD_GROUP_API_URL, config, payload, d)
 }
 
 func dashboardgroupRead(d *schema.ResourceData, meta interface{}) error {
 	config := meta.(*signalformConfig)
 	url := fmt.Sprintf("%s/%s", DASHBOARD_GROUP_API_URL, d.Id())
 
-	return resourceRead(url, config.AuthToken, d)
+	return resourceReadWithState(url, config, d, dashboardGroupAPIToState)
+}
+
+/*
+  Mirrors and dashboards change the lastUpdated of their group without changing what this resource manages,
+  so the group is only out of sync when its own fields differ from the state
+*/
+func dashboardGroupAPIToState(group map[string]interface{}, d *schema.ResourceData) error {
+	if !d.Get("synced").(bool) {
+		description, _ := group["description"].(string)
+		teams, _ := group["teams"].([]interface{})
+		if teams == nil {
+			teams = make([]interface{}, 0)
+		}
+		d.Set("synced", group["name"] == d.Get("name") && description == d.Get("description") && reflect.DeepEqual(teams, d.Get("teams")))
+	}
+	return permissionsAPIToState(group, d)
 }
 
 func dashboardgroupUpdate(d *schema.ResourceData, meta interface{}) error {
@@ -90,12 +175,35 @@ func dashboardgroupUpdate(d *schema.ResourceData, meta interface{}) error {
 		return fmt.Errorf("Failed creating json payload: %s", err.Error())
 	}
 	url := fmt.Sprintf("%s/%s", DASHBOARD_GROUP_API_URL, d.Id())
+	payload, err = keepDashboardGroupConfigs(payload, url, config)
+	if err != nil {
+		return err
+	}
+
+	return resourceUpdate(url, config, payload, d)
+}
 
-	return resourceUpdate(url, config.AuthToken, payload, d)
+/*
+  The dashboardConfigs of the group hold its mirrors, managed with signalform_dashboard_mirror resources, and
+  must be sent back as they are
+*/
+func keepDashboardGroupConfigs(payload []byte, url string, config *signalformConfig) ([]byte, error) {
+	decoded := map[string]interface{}{}
+	if err := json.Unmarshal(payload, &decoded); err != nil {
+		return nil, fmt.Errorf("Failed creating json payload: %s", err.Error())
+	}
+	group, err := getSignalFxObject(url, config)
+	if err != nil {
+		return nil, fmt.Errorf("dashboard group %s: %s", url, err.Error())
+	}
+	if configs, ok := group["dashboardConfigs"]; ok {
+		decoded["dashboardConfigs"] = configs
+	}
+	return json.Marshal(decoded)
 }
 
 func dashboardgroupDelete(d *schema.ResourceData, meta interface{}) error {
 	config := meta.(*signalformConfig)
 	url := fmt.Sprintf("%s/%s", DASHBOARD_GROUP_API_URL, d.Id())
-	return resourceDelete(url, config.AuthToken, d)
+	return resourceDelete(url, config, d)
 }
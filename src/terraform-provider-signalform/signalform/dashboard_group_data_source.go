@@ -0,0 +1,59 @@
+package signalform
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+/*
+  Data source looking up a dashboard group by name, so that configurations do not hard-code its ID
+*/
+func dashboardGroupDataSource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Name of the dashboard group. It must match exactly one dashboard group",
+			},
+			"description": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Description of the dashboard group",
+			},
+			"dashboards": &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "IDs of the dashboards of the dashboard group",
+			},
+		},
+
+		Read: dashboardGroupDataSourceRead,
+	}
+}
+
+func dashboardGroupDataSourceRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*signalformConfig)
+	name := d.Get("name").(string)
+
+	groups, err := searchSignalFxObjects(DASHBOARD_GROUP_API_URL, url.Values{"name": []string{name}}, config)
+	if err != nil {
+		return fmt.Errorf("Searching dashboard groups: %s", err.Error())
+	}
+	group, err := findDashboardGroupByName(groups, name)
+	if err != nil {
+		return err
+	}
+	d.SetId(group["id"].(string))
+	description, _ := group["description"].(string)
+	d.Set("description", description)
+	dashboards, _ := group["dashboards"].([]interface{})
+	return d.Set("dashboards", dashboards)
+}
+
+func findDashboardGroupByName(groups []map[string]interface{}, name string) (map[string]interface{}, error) {
+	return findObjectByName("dashboard group", groups, name)
+}
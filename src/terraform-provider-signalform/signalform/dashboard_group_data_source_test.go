@@ -0,0 +1,28 @@
+package signalform
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFindDashboardGroupByName(t *testing.T) {
+	groups := []map[string]interface{}{
+		map[string]interface{}{"id": "first", "name": "Web"},
+		map[string]interface{}{"id": "second", "name": "Web frontend"},
+	}
+	group, err := findDashboardGroupByName(groups, "Web")
+	assert.Nil(t, err)
+	assert.Equal(t, "first", group["id"])
+
+	_, err = findDashboardGroupByName(groups, "Database")
+	assert.NotNil(t, err)
+}
+
+func TestFindDashboardGroupByNameAmbiguous(t *testing.T) {
+	groups := []map[string]interface{}{
+		map[string]interface{}{"id": "first", "name": "Web"},
+		map[string]interface{}{"id": "second", "name": "Web"},
+	}
+	_, err := findDashboardGroupByName(groups, "Web")
+	assert.Contains(t, err.Error(), "2 dashboard groups are named Web")
+}
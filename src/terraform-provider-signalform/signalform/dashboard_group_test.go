@@ -0,0 +1,94 @@
+package signalform
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetPayloadDashboardGroupTeams(t *testing.T) {
+	d := dashboardGroupResource().TestResourceData()
+	d.Set("name", "group")
+	d.Set("teams", []interface{}{"team1", "team2"})
+
+	payload, err := getPayloadDashboardGroup(d)
+	assert.Nil(t, err)
+	decoded := map[string]interface{}{}
+	json.Unmarshal(payload, &decoded)
+	assert.Equal(t, []interface{}{"team1", "team2"}, decoded["teams"])
+}
+
+func TestGetPayloadDashboardGroupNoTeams(t *testing.T) {
+	d := dashboardGroupResource().TestResourceData()
+	d.Set("name", "group")
+
+	payload, err := getPayloadDashboardGroup(d)
+	assert.Nil(t, err)
+	decoded := map[string]interface{}{}
+	json.Unmarshal(payload, &decoded)
+	assert.Equal(t, []interface{}{}, decoded["teams"])
+}
+
+func TestGetPayloadDashboardGroupPermissions(t *testing.T) {
+	d := dashboardGroupResource().TestResourceData()
+	d.Set("name", "group")
+	d.Set("permissions", []interface{}{
+		map[string]interface{}{
+			"acl": []interface{}{
+				map[string]interface{}{"principal_id": "team1", "principal_type": "TEAM", "actions": []interface{}{"WRITE"}},
+			},
+		},
+	})
+
+	payload, err := getPayloadDashboardGroup(d)
+	assert.Nil(t, err)
+	decoded := map[string]interface{}{}
+	json.Unmarshal(payload, &decoded)
+	acl := decoded["permissions"].(map[string]interface{})["acl"].([]interface{})
+	assert.Equal(t, 1, len(acl))
+	assert.Equal(t, "team1", acl[0].(map[string]interface{})["principalId"])
+}
+
+func TestKeepDashboardGroupConfigs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+		w.Write([]byte(`{"id": "group", "name": "Old name", "dashboardConfigs": [{"dashboardId": "mirrored", "nameOverride": "Mirror"}]}`))
+	}))
+	defer server.Close()
+
+	d := dashboardGroupResource().TestResourceData()
+	d.Set("name", "New name")
+	payload, err := getPayloadDashboardGroup(d)
+	assert.Nil(t, err)
+	payload, err = keepDashboardGroupConfigs(payload, server.URL, &signalformConfig{AuthToken: "token"})
+	assert.Nil(t, err)
+	decoded := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal(payload, &decoded))
+	assert.Equal(t, "New name", decoded["name"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"dashboardId": "mirrored", "nameOverride": "Mirror"},
+	}, decoded["dashboardConfigs"])
+}
+
+func TestDashboardGroupAPIToStateSynced(t *testing.T) {
+	d := dashboardGroupResource().TestResourceData()
+	d.Set("name", "group")
+	d.Set("teams", []interface{}{"team1"})
+	group := map[string]interface{}{
+		"name":             "group",
+		"teams":            []interface{}{"team1"},
+		"dashboardConfigs": []interface{}{map[string]interface{}{"dashboardId": "mirrored"}},
+	}
+
+	// A new mirror only changes the dashboardConfigs
+	d.Set("synced", false)
+	assert.Nil(t, dashboardGroupAPIToState(group, d))
+	assert.Equal(t, true, d.Get("synced"))
+
+	group["description"] = "Changed in the UI"
+	d.Set("synced", false)
+	assert.Nil(t, dashboardGroupAPIToState(group, d))
+	assert.Equal(t, false, d.Get("synced"))
+}
@@ -0,0 +1,79 @@
+package signalform
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func dashboardMigrateState(v int, is *terraform.InstanceState, meta interface{}) (*terraform.InstanceState, error) {
+	var err error
+	switch v {
+	case 0:
+		log.Println("[INFO] Found Dashboard State v0; migrating to v1")
+		if is, err = migrateDashboardStateV0toV1(is); err != nil {
+			return is, err
+		}
+		fallthrough
+	case 1:
+		log.Println("[INFO] Found Dashboard State v1; migrating to v2")
+		return migrateDashboardStateV1toV2(is)
+	default:
+		return is, fmt.Errorf("Unexpected schema version: %d", v)
+	}
+}
+
+/*
+  Version 1 turned variable from a set into a list, so that variables keep the order of the configuration.
+*/
+func migrateDashboardStateV0toV1(is *terraform.InstanceState) (*terraform.InstanceState, error) {
+	return migrateSetToList(is, "variable")
+}
+
+/*
+  Version 2 turned chart from a set into a list, so that the automatic layout places charts in the order
+  of the configuration.
+*/
+func migrateDashboardStateV1toV2(is *terraform.InstanceState) (*terraform.InstanceState, error) {
+	return migrateSetToList(is, "chart")
+}
+
+/*
+  Set elements are indexed by their hash in the state, they are renumbered in the order of their hashes:
+  the next plan then only updates the dashboard if the order of the configuration is different.
+*/
+func migrateSetToList(is *terraform.InstanceState, attribute string) (*terraform.InstanceState, error) {
+	if is.Empty() {
+		log.Println("[DEBUG] Empty InstanceState; nothing to migrate.")
+		return is, nil
+	}
+
+	hashes := make([]string, 0)
+	seen := make(map[string]bool)
+	for key := range is.Attributes {
+		parts := strings.SplitN(key, ".", 3)
+		if len(parts) == 3 && parts[0] == attribute && !seen[parts[1]] {
+			seen[parts[1]] = true
+			hashes = append(hashes, parts[1])
+		}
+	}
+	sort.Strings(hashes)
+	indexes := make(map[string]int)
+	for i, hash := range hashes {
+		indexes[hash] = i
+	}
+
+	attributes := make(map[string]string)
+	for key, value := range is.Attributes {
+		parts := strings.SplitN(key, ".", 3)
+		if len(parts) == 3 && parts[0] == attribute {
+			key = fmt.Sprintf("%s.%d.%s", attribute, indexes[parts[1]], parts[2])
+		}
+		attributes[key] = value
+	}
+	is.Attributes = attributes
+	return is, nil
+}
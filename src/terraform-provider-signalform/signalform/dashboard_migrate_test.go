@@ -0,0 +1,70 @@
+package signalform
+
+import (
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMigrateDashboardStateV0toV1(t *testing.T) {
+	is := &terraform.InstanceState{
+		ID: "dashboard",
+		Attributes: map[string]string{
+			"name":                             "Dashboard",
+			"variable.#":                       "2",
+			"variable.2345.property":           "region",
+			"variable.2345.values.#":           "1",
+			"variable.2345.values.3456":        "us-west-1",
+			"variable.1234.property":           "env",
+			"variable.1234.values_suggested.#": "0",
+		},
+	}
+
+	is, err := dashboardMigrateState(0, is, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{
+		"name":                          "Dashboard",
+		"variable.#":                    "2",
+		"variable.1.property":           "region",
+		"variable.1.values.#":           "1",
+		"variable.1.values.3456":        "us-west-1",
+		"variable.0.property":           "env",
+		"variable.0.values_suggested.#": "0",
+	}, is.Attributes)
+}
+
+func TestMigrateDashboardStateV1toV2(t *testing.T) {
+	is := &terraform.InstanceState{
+		ID: "dashboard",
+		Attributes: map[string]string{
+			"name":                 "Dashboard",
+			"chart.#":              "2",
+			"chart.987.chart_id":   "second",
+			"chart.987.width":      "6",
+			"chart.123.chart_id":   "first",
+			"chart.123.width":      "12",
+			"variable.0.property":  "env",
+			"grid.555.chart_ids.#": "1",
+			"grid.555.chart_ids.0": "third",
+		},
+	}
+
+	is, err := dashboardMigrateState(1, is, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{
+		"name":                 "Dashboard",
+		"chart.#":              "2",
+		"chart.1.chart_id":     "second",
+		"chart.1.width":        "6",
+		"chart.0.chart_id":     "first",
+		"chart.0.width":        "12",
+		"variable.0.property":  "env",
+		"grid.555.chart_ids.#": "1",
+		"grid.555.chart_ids.0": "third",
+	}, is.Attributes)
+}
+
+func TestMigrateDashboardStateUnknownVersion(t *testing.T) {
+	_, err := dashboardMigrateState(3, &terraform.InstanceState{}, nil)
+	assert.NotNil(t, err)
+}
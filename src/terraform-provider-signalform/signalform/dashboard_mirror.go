@@ -0,0 +1,288 @@
+package signalform
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+/*
+  Mirrors are stored in the dashboardConfigs of the mirroring group, which are read, modified and written
+  back as a whole: modifications of the same group must not interleave.
+*/
+var dashboardMirrorLock sync.Mutex
+
+func dashboardMirrorResource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"dashboard_group": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "ID of the dashboard group in which the dashboard is mirrored",
+			},
+			"dashboard": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "ID of the mirrored dashboard",
+			},
+			"name_override": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Name of the dashboard in the mirroring group, instead of the one of the mirrored dashboard",
+			},
+			"description_override": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Description of the dashboard in the mirroring group, instead of the one of the mirrored dashboard",
+			},
+			"filter_override": &schema.Schema{
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "Filter to apply to each chart of the mirror, instead of the filters of the mirrored dashboard",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"property": &schema.Schema{
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "A metric time series dimension or property name",
+						},
+						"negated": &schema.Schema{
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "(false by default) Whether this filter should be a \"not\" filter",
+						},
+						"values": &schema.Schema{
+							Type:        schema.TypeSet,
+							Required:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString, ValidateFunc: validateFilterValue},
+							Description: "List of strings (which will be treated as an OR filter on the property)",
+						},
+					},
+				},
+			},
+			"variable_override": &schema.Schema{
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "Default values of the dashboard variables in the mirror, instead of the ones of the mirrored dashboard",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"property": &schema.Schema{
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "Property of the dashboard variable to override",
+						},
+						"values": &schema.Schema{
+							Type:        schema.TypeSet,
+							Optional:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString, ValidateFunc: validateFilterValue},
+							Description: "List of strings (which will be treated as an OR filter on the property)",
+						},
+						"values_suggested": &schema.Schema{
+							Type:        schema.TypeSet,
+							Optional:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "A list of strings of suggested values for this variable",
+						},
+					},
+				},
+			},
+		},
+
+		Create: dashboardmirrorCreate,
+		Read:   dashboardmirrorRead,
+		Update: dashboardmirrorUpdate,
+		Delete: dashboardmirrorDelete,
+		Importer: &schema.ResourceImporter{
+			State: dashboardmirrorImport,
+		},
+	}
+}
+
+/*
+  Use Resource object to construct the dashboardConfigs entry of the mirror
+*/
+func getDashboardMirrorConfig(d *schema.ResourceData) map[string]interface{} {
+	config := map[string]interface{}{
+		"dashboardId": d.Get("dashboard").(string),
+	}
+	if val, ok := d.GetOk("name_override"); ok {
+		config["nameOverride"] = val.(string)
+	}
+	if val, ok := d.GetOk("description_override"); ok {
+		config["descriptionOverride"] = val.(string)
+	}
+
+	filters := d.Get("filter_override").(*schema.Set).List()
+	sources := make([]map[string]interface{}, len(filters))
+	for i, filter := range filters {
+		filter := filter.(map[string]interface{})
+		sources[i] = map[string]interface{}{
+			"property": filter["property"].(string),
+			"NOT":      filter["negated"].(bool),
+			"values":   filter["values"].(*schema.Set).List(),
+		}
+	}
+	tf_variables := d.Get("variable_override").(*schema.Set).List()
+	variables := make([]map[string]interface{}, len(tf_variables))
+	for i, variable := range tf_variables {
+		variable := variable.(map[string]interface{})
+		item := map[string]interface{}{
+			"property": variable["property"].(string),
+			"values":   variable["values"].(*schema.Set).List(),
+		}
+		if suggested := variable["values_suggested"].(*schema.Set).List(); len(suggested) > 0 {
+			item["preferredSuggestions"] = suggested
+		}
+		variables[i] = item
+	}
+	if len(sources) > 0 || len(variables) > 0 {
+		config["filtersOverride"] = map[string]interface{}{
+			"sources":   sources,
+			"variables": variables,
+		}
+	}
+	return config
+}
+
+/*
+  Reads the mirror overrides from its dashboardConfigs entry
+*/
+func dashboardMirrorConfigToState(config map[string]interface{}, d *schema.ResourceData) error {
+	nameOverride, _ := config["nameOverride"].(string)
+	d.Set("name_override", nameOverride)
+	descriptionOverride, _ := config["descriptionOverride"].(string)
+	d.Set("description_override", descriptionOverride)
+
+	filters := make([]interface{}, 0)
+	variables := make([]interface{}, 0)
+	if overrides, ok := config["filtersOverride"].(map[string]interface{}); ok {
+		sources, _ := overrides["sources"].([]interface{})
+		for _, source := range sources {
+			source := source.(map[string]interface{})
+			negated, _ := source["NOT"].(bool)
+			filters = append(filters, map[string]interface{}{
+				"property": source["property"],
+				"negated":  negated,
+				"values":   schema.NewSet(schema.HashString, toInterfaceList(source["values"])),
+			})
+		}
+		tf_variables, _ := overrides["variables"].([]interface{})
+		for _, variable := range tf_variables {
+			variable := variable.(map[string]interface{})
+			variables = append(variables, map[string]interface{}{
+				"property":         variable["property"],
+				"values":           schema.NewSet(schema.HashString, toInterfaceList(variable["values"])),
+				"values_suggested": schema.NewSet(schema.HashString, toInterfaceList(variable["preferredSuggestions"])),
+			})
+		}
+	}
+	if err := d.Set("filter_override", filters); err != nil {
+		return err
+	}
+	return d.Set("variable_override", variables)
+}
+
+func toInterfaceList(v interface{}) []interface{} {
+	if list, ok := v.([]interface{}); ok {
+		return list
+	}
+	return make([]interface{}, 0)
+}
+
+/*
+  Applies modify to the dashboardConfigs of a dashboard group and writes the group back
+*/
+func updateDashboardGroupConfigs(groupId string, config *signalformConfig, modify func([]interface{}) ([]interface{}, error)) error {
+	url := fmt.Sprintf("%s/%s", DASHBOARD_GROUP_API_URL, groupId)
+	return updateObjectEntries(&dashboardMirrorLock, url, "dashboard group "+groupId, config, "dashboardConfigs", modify)
+}
+
+func dashboardmirrorCreate(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*signalformConfig)
+	groupId := d.Get("dashboard_group").(string)
+	dashboardId := d.Get("dashboard").(string)
+
+	err := updateDashboardGroupConfigs(groupId, config, func(configs []interface{}) ([]interface{}, error) {
+		if findObjectEntry(configs, "dashboardId", dashboardId) != -1 {
+			return nil, fmt.Errorf("Dashboard %s is already in the dashboard group %s", dashboardId, groupId)
+		}
+		return append(configs, getDashboardMirrorConfig(d)), nil
+	})
+	if err != nil {
+		return err
+	}
+	d.SetId(fmt.Sprintf("%s/%s", groupId, dashboardId))
+	return dashboardmirrorRead(d, meta)
+}
+
+func dashboardmirrorRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*signalformConfig)
+	groupId := d.Get("dashboard_group").(string)
+	url := fmt.Sprintf("%s/%s", DASHBOARD_GROUP_API_URL, groupId)
+	mirrorConfig, err := readObjectEntry(url, "dashboard group "+groupId, config, "dashboardConfigs", "dashboardId", d.Get("dashboard").(string))
+	if err != nil {
+		return err
+	}
+	if mirrorConfig == nil {
+		d.SetId("")
+		return nil
+	}
+	return dashboardMirrorConfigToState(mirrorConfig, d)
+}
+
+func dashboardmirrorUpdate(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*signalformConfig)
+	dashboardId := d.Get("dashboard").(string)
+
+	err := updateDashboardGroupConfigs(d.Get("dashboard_group").(string), config, func(configs []interface{}) ([]interface{}, error) {
+		mirrorConfig := getDashboardMirrorConfig(d)
+		index := findObjectEntry(configs, "dashboardId", dashboardId)
+		if index == -1 {
+			return append(configs, mirrorConfig), nil
+		}
+		// The configId identifies the entry for the API
+		if configId, ok := configs[index].(map[string]interface{})["configId"]; ok {
+			mirrorConfig["configId"] = configId
+		}
+		configs[index] = mirrorConfig
+		return configs, nil
+	})
+	if err != nil {
+		return err
+	}
+	return dashboardmirrorRead(d, meta)
+}
+
+func dashboardmirrorDelete(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*signalformConfig)
+	dashboardId := d.Get("dashboard").(string)
+
+	err := updateDashboardGroupConfigs(d.Get("dashboard_group").(string), config, func(configs []interface{}) ([]interface{}, error) {
+		if index := findObjectEntry(configs, "dashboardId", dashboardId); index != -1 {
+			configs = append(configs[:index], configs[index+1:]...)
+		}
+		return configs, nil
+	})
+	if err != nil {
+		return err
+	}
+	d.SetId("")
+	return nil
+}
+
+/*
+  Mirrors are imported with an ID of the form <dashboard group ID>/<dashboard ID>
+*/
+func dashboardmirrorImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	groupId, dashboardId, err := parseObjectEntryId(d.Id(), "<dashboard group ID>/<dashboard ID>")
+	if err != nil {
+		return nil, err
+	}
+	d.Set("dashboard_group", groupId)
+	d.Set("dashboard", dashboardId)
+	return []*schema.ResourceData{d}, nil
+}
@@ -0,0 +1,69 @@
+package signalform
+
+import (
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGetDashboardMirrorConfig(t *testing.T) {
+	d := dashboardMirrorResource().TestResourceData()
+	d.Set("dashboard_group", "group")
+	d.Set("dashboard", "dash")
+	d.Set("name_override", "Prod overview")
+	d.Set("filter_override", []interface{}{
+		map[string]interface{}{"property": "env", "negated": false, "values": []interface{}{"prod"}},
+	})
+
+	config := getDashboardMirrorConfig(d)
+	assert.Equal(t, "dash", config["dashboardId"])
+	assert.Equal(t, "Prod overview", config["nameOverride"])
+	assert.NotContains(t, config, "descriptionOverride")
+	overrides := config["filtersOverride"].(map[string]interface{})
+	sources := overrides["sources"].([]map[string]interface{})
+	assert.Equal(t, 1, len(sources))
+	assert.Equal(t, "env", sources[0]["property"])
+	assert.Equal(t, []interface{}{"prod"}, sources[0]["values"])
+	assert.Equal(t, 0, len(overrides["variables"].([]map[string]interface{})))
+}
+
+func TestGetDashboardMirrorConfigNoOverride(t *testing.T) {
+	d := dashboardMirrorResource().TestResourceData()
+	d.Set("dashboard", "dash")
+
+	config := getDashboardMirrorConfig(d)
+	assert.Equal(t, map[string]interface{}{"dashboardId": "dash"}, config)
+}
+
+func TestDashboardMirrorConfigToState(t *testing.T) {
+	d := dashboardMirrorResource().TestResourceData()
+	err := dashboardMirrorConfigToState(map[string]interface{}{
+		"dashboardId":  "dash",
+		"nameOverride": "Prod overview",
+		"filtersOverride": map[string]interface{}{
+			"sources": []interface{}{
+				map[string]interface{}{"property": "env", "NOT": true, "values": []interface{}{"dev"}},
+			},
+			"variables": []interface{}{
+				map[string]interface{}{"property": "service", "values": []interface{}{"api"}},
+			},
+		},
+	}, d)
+	assert.Nil(t, err)
+	assert.Equal(t, "Prod overview", d.Get("name_override"))
+	assert.Equal(t, 1, d.Get("filter_override").(*schema.Set).Len())
+	assert.Equal(t, 1, d.Get("variable_override").(*schema.Set).Len())
+}
+
+func TestDashboardMirrorImport(t *testing.T) {
+	d := dashboardMirrorResource().TestResourceData()
+	d.SetId("group/dash")
+	_, err := dashboardmirrorImport(d, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "group", d.Get("dashboard_group"))
+	assert.Equal(t, "dash", d.Get("dashboard"))
+
+	d.SetId("dash")
+	_, err = dashboardmirrorImport(d, nil)
+	assert.NotNil(t, err)
+}
@@ -1,7 +1,11 @@
 package signalform
 
 import (
+	"encoding/json"
+	"github.com/hashicorp/terraform/configs/hcl2shim"
 	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
@@ -16,3 +20,276 @@ func TestValidateChartsResolutionNotAllowed(t *testing.T) {
 	_, errors := validateChartsResolution("whatever", "charts_resolution")
 	assert.Equal(t, len(errors), 1)
 }
+
+func TestGetDashboardMirrorGroupIds(t *testing.T) {
+	groups := []map[string]interface{}{
+		map[string]interface{}{
+			"id": "owner",
+			"dashboardConfigs": []interface{}{
+				map[string]interface{}{"dashboardId": "dash"},
+			},
+		},
+		map[string]interface{}{
+			"id": "mirror",
+			"dashboardConfigs": []interface{}{
+				map[string]interface{}{"dashboardId": "other"},
+				map[string]interface{}{"dashboardId": "dash"},
+			},
+		},
+		map[string]interface{}{
+			"id": "unrelated",
+			"dashboardConfigs": []interface{}{
+				map[string]interface{}{"dashboardId": "other"},
+			},
+		},
+		map[string]interface{}{
+			"id": "empty",
+		},
+	}
+	configGroupIds := getDashboardConfigGroupIds(groups)
+	assert.Equal(t, map[string][]string{"dash": []string{"owner", "mirror"}, "other": []string{"mirror", "unrelated"}}, configGroupIds)
+	assert.Equal(t, []string{"mirror"}, getDashboardMirrorGroupIds(configGroupIds["dash"], "owner"))
+	assert.Equal(t, []string{}, getDashboardMirrorGroupIds(configGroupIds["unknown"], "owner"))
+}
+
+func TestGetDashboardEventOverlaysDetectorId(t *testing.T) {
+	overlays, err := getDashboardEventOverlays([]interface{}{
+		map[string]interface{}{"signal": "", "type": "detectorEvents", "detector_id": "detector1"},
+	})
+	assert.Nil(t, err)
+	eventSignal := overlays[0]["eventSignal"].(map[string]interface{})
+	assert.Equal(t, "detector1", eventSignal["detectorId"])
+	assert.Equal(t, "detectorEvents", eventSignal["eventType"])
+}
+
+func TestGetDashboardEventOverlaysDetectorIdWrongType(t *testing.T) {
+	_, err := getDashboardEventOverlays([]interface{}{
+		map[string]interface{}{"signal": "", "type": "eventTimeSeries", "detector_id": "detector1"},
+	})
+	assert.NotNil(t, err)
+}
+
+func TestGetDashboardEventOverlaysNoSignal(t *testing.T) {
+	_, err := getDashboardEventOverlays([]interface{}{
+		map[string]interface{}{"signal": "", "type": "eventTimeSeries", "detector_id": ""},
+	})
+	assert.NotNil(t, err)
+}
+
+func TestGetChartsResolution(t *testing.T) {
+	assert.Equal(t, "highest", getChartsResolution("HIGHEST"))
+	assert.Equal(t, "low", getChartsResolution("low"))
+	assert.Equal(t, "default", getChartsResolution(""))
+	assert.Equal(t, "default", getChartsResolution(nil))
+}
+
+func TestGetGridCharts(t *testing.T) {
+	charts := getGridCharts(map[string]interface{}{
+		"chart_ids":    []interface{}{"a", "b", "c", "d"},
+		"start_row":    0,
+		"start_column": 0,
+		"width":        4,
+		"height":       1,
+		"chart_override": []interface{}{
+			map[string]interface{}{"chart_id": "b", "width": 8, "height": 2},
+		},
+	})
+	assert.Equal(t, 4, len(charts))
+	assert.Equal(t, map[string]interface{}{"chartId": "a", "row": 0, "column": 0, "width": 4, "height": 1}, charts[0])
+	assert.Equal(t, map[string]interface{}{"chartId": "b", "row": 0, "column": 4, "width": 8, "height": 2}, charts[1])
+	// The second row starts below the taller overridden chart
+	assert.Equal(t, map[string]interface{}{"chartId": "c", "row": 2, "column": 0, "width": 4, "height": 1}, charts[2])
+	assert.Equal(t, map[string]interface{}{"chartId": "d", "row": 2, "column": 4, "width": 4, "height": 1}, charts[3])
+}
+
+func TestGetGridChartsRowStep(t *testing.T) {
+	// Without overrides, rows advance by 1 whatever the height of the grid
+	charts := getGridCharts(map[string]interface{}{
+		"chart_ids":    []interface{}{"a", "b", "c"},
+		"start_row":    3,
+		"start_column": 0,
+		"width":        6,
+		"height":       2,
+	})
+	assert.Equal(t, 3, charts[0]["row"])
+	assert.Equal(t, 3, charts[1]["row"])
+	assert.Equal(t, 4, charts[2]["row"])
+
+	// The height of an overridden chart counts for the row it is placed on
+	charts = getGridCharts(map[string]interface{}{
+		"chart_ids":    []interface{}{"a", "b", "c", "d"},
+		"start_row":    0,
+		"start_column": 0,
+		"width":        6,
+		"height":       1,
+		"chart_override": []interface{}{
+			map[string]interface{}{"chart_id": "c", "width": 0, "height": 3},
+		},
+	})
+	assert.Equal(t, []interface{}{0, 0, 1, 1}, []interface{}{charts[0]["row"], charts[1]["row"], charts[2]["row"], charts[3]["row"]})
+}
+
+func TestCheckGridOverrides(t *testing.T) {
+	grid := map[string]interface{}{
+		"chart_ids": []interface{}{"a", "b"},
+		"chart_override": []interface{}{
+			map[string]interface{}{"chart_id": "b", "width": 8, "height": 0},
+		},
+	}
+	assert.Nil(t, checkGridOverrides(grid))
+
+	grid["chart_override"] = []interface{}{
+		map[string]interface{}{"chart_id": "c", "width": 8, "height": 0},
+	}
+	assert.EqualError(t, checkGridOverrides(grid), "chart_override of chart c, which is not in the chart_ids of its grid")
+
+	// Charts which are not created yet can't be checked
+	grid["chart_ids"] = []interface{}{"a", hcl2shim.UnknownVariableValue}
+	assert.Nil(t, checkGridOverrides(grid))
+}
+
+func TestGetAutoColumnNumbers(t *testing.T) {
+	columns := []interface{}{
+		map[string]interface{}{"column": 2, "width": 3, "auto_column": true},
+		map[string]interface{}{"column": 0, "width": 4, "auto_column": true},
+		map[string]interface{}{"column": 6, "width": 6, "auto_column": false},
+		map[string]interface{}{"column": 1, "width": 2, "auto_column": true},
+	}
+	assert.Equal(t, map[int]int{1: 0, 3: 4, 0: 6}, getAutoColumnNumbers(columns))
+}
+
+func TestCheckAutoColumns(t *testing.T) {
+	columns := []interface{}{
+		map[string]interface{}{"column": 0, "width": 4, "auto_column": true},
+		map[string]interface{}{"column": 1, "width": 8, "auto_column": true},
+		map[string]interface{}{"column": 0, "width": 12, "auto_column": false},
+	}
+	assert.Nil(t, checkAutoColumns(columns))
+
+	columns = append(columns, map[string]interface{}{"column": 2, "width": 3, "auto_column": true})
+	assert.EqualError(t, checkAutoColumns(columns), "the auto placed columns are 15 columns wide, a dashboard has 12")
+}
+
+func TestPackDashboardCharts(t *testing.T) {
+	charts := packDashboardCharts([]map[string]interface{}{
+		map[string]interface{}{"chartId": "c", "row": 1, "column": 0, "width": 6, "height": 2},
+		map[string]interface{}{"chartId": "a", "row": 0, "column": 0, "width": 6, "height": 1},
+		map[string]interface{}{"chartId": "b", "row": 0, "column": 1, "width": 4, "height": 1},
+		map[string]interface{}{"chartId": "d", "row": 2, "column": 0, "width": 8, "height": 1},
+	}, []map[string]interface{}{})
+	// Charts are placed in the order they are declared, whatever their row and column
+	assert.Equal(t, map[string]interface{}{"chartId": "c", "row": 0, "column": 0, "width": 6, "height": 2}, charts[0])
+	assert.Equal(t, map[string]interface{}{"chartId": "a", "row": 0, "column": 6, "width": 6, "height": 1}, charts[1])
+	// Fills the space left under a, next to the taller c
+	assert.Equal(t, map[string]interface{}{"chartId": "b", "row": 1, "column": 6, "width": 4, "height": 1}, charts[2])
+	assert.Equal(t, map[string]interface{}{"chartId": "d", "row": 2, "column": 0, "width": 8, "height": 1}, charts[3])
+}
+
+func TestPackDashboardChartsReserved(t *testing.T) {
+	charts := packDashboardCharts([]map[string]interface{}{
+		map[string]interface{}{"chartId": "a", "row": 0, "column": 0, "width": 6, "height": 1},
+		map[string]interface{}{"chartId": "b", "row": 0, "column": 0, "width": 12, "height": 1},
+	}, []map[string]interface{}{
+		map[string]interface{}{"chartId": "grid", "row": 0, "column": 0, "width": 6, "height": 2},
+		map[string]interface{}{"chartId": "column", "row": 1, "column": 6, "width": 6, "height": 1},
+	})
+	assert.Equal(t, map[string]interface{}{"chartId": "a", "row": 0, "column": 6, "width": 6, "height": 1}, charts[0])
+	assert.Equal(t, map[string]interface{}{"chartId": "b", "row": 2, "column": 0, "width": 12, "height": 1}, charts[1])
+}
+
+func TestValidateDashboardLayout(t *testing.T) {
+	_, errors := validateDashboardLayout("auto", "layout")
+	assert.Equal(t, 0, len(errors))
+	_, errors = validateDashboardLayout("grid", "layout")
+	assert.Equal(t, 1, len(errors))
+}
+
+func TestGetPayloadDashboardRawJson(t *testing.T) {
+	d := dashboardResource().TestResourceData()
+	d.Set("name", "Dashboard")
+	d.Set("dashboard_group", "group")
+	payload, err := getPayloadDashboardRawJson(d, `{"id": "old", "name": "Exported", "groupId": "old", "chartDensity": "HIGH"}`, &signalformConfig{})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"chartDensity":"HIGH","groupId":"group","name":"Dashboard"}`, string(payload))
+}
+
+func TestValidateDashboardRawJson(t *testing.T) {
+	_, errors := validateDashboardRawJson(`{"name": "Exported", "charts": []}`, "raw_json")
+	assert.Equal(t, 0, len(errors))
+
+	_, errors = validateDashboardRawJson(`[]`, "raw_json")
+	assert.Equal(t, 1, len(errors))
+
+	_, errors = validateDashboardRawJson(`{"packageType": "DASHBOARD", "dashboardExport": {"dashboard": {"name": "Exported"}}}`, "raw_json")
+	assert.Equal(t, 1, len(errors))
+	assert.Contains(t, errors[0].Error(), "exported from the SignalFx UI, which is not supported")
+}
+
+func TestGetDashboardRawJson(t *testing.T) {
+	dashboard := map[string]interface{}{
+		"id":           "new",
+		"name":         "Dashboard",
+		"chartDensity": "LOW",
+		"charts":       []interface{}{},
+	}
+	normalized, err := getDashboardRawJson(dashboard, `{"id": "old", "chartDensity": "HIGH"}`)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"chartDensity":"LOW","id":"old"}`, normalized)
+}
+
+func TestCountDashboardCharts(t *testing.T) {
+	d := dashboardResource().TestResourceData()
+	d.Set("chart", []interface{}{
+		map[string]interface{}{"chart_id": "a", "row": 0, "column": 0, "width": 12, "height": 1},
+	})
+	d.Set("grid", []interface{}{
+		map[string]interface{}{"chart_ids": []interface{}{"b", "c"}, "start_row": 1, "start_column": 0, "width": 6, "height": 1},
+	})
+	d.Set("column", []interface{}{
+		map[string]interface{}{"chart_ids": []interface{}{"d", "e", "f"}, "column": 0, "start_row": 2, "width": 12, "height": 1},
+	})
+	assert.Equal(t, 6, countDashboardCharts(d.Get))
+
+	d = dashboardResource().TestResourceData()
+	d.Set("raw_json", `{"charts": [{"chartId": "a"}, {"chartId": "b"}]}`)
+	assert.Equal(t, 2, countDashboardCharts(d.Get))
+}
+
+func TestGetDashboardGroupIdDefault(t *testing.T) {
+	config := &signalformConfig{DefaultDashboardGroup: "G1"}
+	d := dashboardResource().TestResourceData()
+	assert.Equal(t, "", getDashboardGroupId(d, &signalformConfig{}))
+	assert.Equal(t, "G1", getDashboardGroupId(d, config))
+
+	d.Set("dashboard_group", "G2")
+	assert.Equal(t, "G2", getDashboardGroupId(d, config))
+}
+
+func TestGetPayloadDashboardUpdateCharts(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(200)
+		w.Write([]byte(`{"id": "dash", "charts": [{"chartId": "placed", "row": 0, "column": 0, "width": 6, "height": 1}]}`))
+	}))
+	defer server.Close()
+
+	// Removing every chart block removes the charts of the dashboard
+	d := dashboardResource().TestResourceData()
+	d.Set("name", "Dashboard")
+	payload, err := getPayloadDashboardUpdate(d, server.URL, &signalformConfig{AuthToken: "token"})
+	assert.Nil(t, err)
+	decoded := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal(payload, &decoded))
+	assert.NotContains(t, decoded, "charts")
+	assert.Equal(t, 0, requests)
+
+	d.Set("charts_managed_externally", true)
+	payload, err = getPayloadDashboardUpdate(d, server.URL, &signalformConfig{AuthToken: "token"})
+	assert.Nil(t, err)
+	decoded = map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal(payload, &decoded))
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"chartId": "placed", "row": 0.0, "column": 0.0, "width": 6.0, "height": 1.0},
+	}, decoded["charts"])
+}
@@ -0,0 +1,85 @@
+package signalform
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+/*
+  Data source listing the dashboards of a dashboard group, e.g. to generate index pages or mirrors of
+  all of them
+*/
+func dashboardsDataSource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"dashboard_group": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "ID of the dashboard group",
+			},
+			"dashboards": &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Dashboards of the dashboard group, in the order of the group",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": &schema.Schema{
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "ID of the dashboard",
+						},
+						"name": &schema.Schema{
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the dashboard",
+						},
+						"url": &schema.Schema{
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "URL of the dashboard",
+						},
+					},
+				},
+			},
+		},
+
+		Read: dashboardsDataSourceRead,
+	}
+}
+
+func dashboardsDataSourceRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*signalformConfig)
+	groupId := d.Get("dashboard_group").(string)
+
+	group, err := getSignalFxObject(fmt.Sprintf("%s/%s", DASHBOARD_GROUP_API_URL, groupId), config)
+	if err != nil {
+		return fmt.Errorf("Reading dashboard group %s: %s", groupId, err.Error())
+	}
+	ids, _ := group["dashboards"].([]interface{})
+	dashboards := make([]map[string]interface{}, 0, len(ids))
+	for _, id := range ids {
+		dashboard, err := getSignalFxObject(fmt.Sprintf("%s/%s", DASHBOARD_API_URL, id), config)
+		if err != nil {
+			return fmt.Errorf("Reading dashboard %s: %s", id, err.Error())
+		}
+		dashboards = append(dashboards, dashboard)
+	}
+
+	d.SetId(groupId)
+	return d.Set("dashboards", getDashboardSummaries(dashboards, config))
+}
+
+func getDashboardSummaries(dashboards []map[string]interface{}, config *signalformConfig) []map[string]interface{} {
+	summaries := make([]map[string]interface{}, len(dashboards))
+	for i, dashboard := range dashboards {
+		id, _ := dashboard["id"].(string)
+		name, _ := dashboard["name"].(string)
+		summaries[i] = map[string]interface{}{
+			"id":   id,
+			"name": name,
+			"url":  getResourceUrl(DASHBOARD_URL, id, config),
+		}
+	}
+	return summaries
+}
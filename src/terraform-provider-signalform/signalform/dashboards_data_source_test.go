@@ -0,0 +1,17 @@
+package signalform
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGetDashboardSummaries(t *testing.T) {
+	dashboards := []map[string]interface{}{
+		map[string]interface{}{"id": "D1", "name": "Latency", "charts": []interface{}{}},
+		map[string]interface{}{"id": "D2", "name": "Errors"},
+	}
+	assert.Equal(t, []map[string]interface{}{
+		map[string]interface{}{"id": "D1", "name": "Latency", "url": "https://app.signalfx.com/#/dashboard/D1"},
+		map[string]interface{}{"id": "D2", "name": "Errors", "url": "https://app.signalfx.com/#/dashboard/D2"},
+	}, getDashboardSummaries(dashboards, &signalformConfig{}))
+}
@@ -4,15 +4,25 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"github.com/hashicorp/terraform/configs/hcl2shim"
+	"github.com/hashicorp/terraform/helper/customdiff"
 	"github.com/hashicorp/terraform/helper/hashcode"
 	"github.com/hashicorp/terraform/helper/schema"
+	"net/url"
+	"regexp"
 	"sort"
 	"strings"
+	"terraform-provider-signalform/internal/sfxtime"
+	"time"
 )
 
+var publishCallRegexp = regexp.MustCompile(`publish\s*\(`)
+var publishLabelRegexp = regexp.MustCompile(`publish\s*\(\s*(?:label\s*=\s*)?(?:'([^']*)'|"([^"]*)")\s*[,)]`)
+
 const (
-	DETECTOR_API_URL = "https://api.signalfx.com/v2/detector"
-	DETECTOR_URL     = "https://app.signalfx.com/#/detector/v2/<id>/edit"
+	DETECTOR_API_URL     = "https://api.signalfx.com/v2/detector"
+	DETECTOR_URL         = "https://app.signalfx.com/#/detector/v2/<id>/edit"
+	ALERT_MUTING_API_URL = "https://api.signalfx.com/v2/alertmuting"
 )
 
 func detectorResource() *schema.Resource {
@@ -29,11 +39,18 @@ func detectorResource() *schema.Resource {
 				Computed:    true,
 				Description: "Latest timestamp the resource was updated",
 			},
+			"protect_from_deletion": protectFromDeletionSchema(),
 			"url": &schema.Schema{
 				Type:        schema.TypeString,
 				Computed:    true,
 				Description: "Url of the detector",
 			},
+			"label_resolutions": &schema.Schema{
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeInt},
+				Description: "Resolution (in milliseconds) at which SignalFx evaluates each detect label of the program text",
+			},
 			"resource_url": &schema.Schema{
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -61,6 +78,12 @@ func detectorResource() *schema.Resource {
 				Description:  "How long (in seconds) to wait for late datapoints. Max value 900s (15m)",
 				ValidateFunc: validateMaxDelayValue,
 			},
+			"min_delay": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Description:  "How long (in seconds) to wait even if the datapoints are arriving in a timely fashion. Max value 900s (15m)",
+				ValidateFunc: validateMaxDelayValue,
+			},
 			"show_data_markers": &schema.Schema{
 				Type:        schema.TypeBool,
 				Optional:    true,
@@ -76,13 +99,12 @@ func detectorResource() *schema.Resource {
 			"disable_sampling": &schema.Schema{
 				Type:        schema.TypeBool,
 				Optional:    true,
-				Default:     false,
-				Description: "(false by default) When false, samples a subset of the output MTS in the visualization.",
+				Description: "(default_disable_sampling of the provider by default) When false, samples a subset of the output MTS in the visualization.",
 			},
 			"time_range": &schema.Schema{
 				Type:          schema.TypeString,
 				Optional:      true,
-				ValidateFunc:  validateSignalfxRelativeTime,
+				ValidateFunc:  sfxtime.Validate,
 				Description:   "From when to display data. SignalFx time syntax (e.g. -5m, -1h)",
 				ConflictsWith: []string{"start_time", "end_time"},
 			},
@@ -98,6 +120,19 @@ func detectorResource() *schema.Resource {
 				ConflictsWith: []string{"time_range"},
 				Description:   "Seconds since epoch. Used for visualization",
 			},
+			"mute_during_update": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "(false by default) When true, the alerts of the detector are muted for mute_during_update_duration from every update, to avoid the alerts fired while its program is replaced",
+			},
+			"mute_during_update_duration": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      300,
+				ValidateFunc: validateMuteDuration,
+				Description:  "(300 by default) How long (in seconds) the alerts are muted when mute_during_update is set. Max value 3600s (1h)",
+			},
 			"tags": &schema.Schema{
 				Type:        schema.TypeList,
 				Optional:    true,
@@ -110,6 +145,39 @@ func detectorResource() *schema.Resource {
 				Elem:        &schema.Schema{Type: schema.TypeString},
 				Description: "Team IDs to associate the detector to",
 			},
+			"authorized_writer_users": &schema.Schema{
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "User IDs allowed to modify the detector. Everyone can if neither users nor teams are set",
+			},
+			"authorized_writer_teams": &schema.Schema{
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Team IDs allowed to modify the detector. Everyone can if neither users nor teams are set",
+			},
+			"severity_notification": &schema.Schema{
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "Notification added to every rule whose severity is at or above min_severity",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"notification": &schema.Schema{
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validateNotification,
+							Description:  "Notification string, in the same format as the notifications of a rule",
+						},
+						"min_severity": &schema.Schema{
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validateSeverity,
+							Description:  "The lowest rule severity the notification applies to, must be one of: Critical, Warning, Major, Minor, Info",
+						},
+					},
+				},
+			},
 			"rule": &schema.Schema{
 				Type:        schema.TypeSet,
 				Required:    true,
@@ -124,9 +192,17 @@ func detectorResource() *schema.Resource {
 						"notifications": &schema.Schema{
 							Type:        schema.TypeList,
 							Optional:    true,
-							Elem:        &schema.Schema{Type: schema.TypeString},
+							Elem:        &schema.Schema{Type: schema.TypeString, ValidateFunc: validateNotification},
 							Description: "List of strings specifying where notifications will be sent when an incident occurs. See https://developers.signalfx.com/v2/docs/detector-model#notifications-models for more info",
 						},
+						"notification": &schema.Schema{
+							Type:        schema.TypeSet,
+							Optional:    true,
+							Description: "Where notifications will be sent when an incident occurs, as a structured alternative to notifications. Not ordered",
+							Elem: &schema.Resource{
+								Schema: notificationSchema(),
+							},
+						},
 						"severity": &schema.Schema{
 							Type:         schema.TypeString,
 							Required:     true,
@@ -145,19 +221,22 @@ func detectorResource() *schema.Resource {
 							Description: "(default: false) When true, notifications and events will not be generated for the detect label",
 						},
 						"parameterized_body": &schema.Schema{
-							Type:        schema.TypeString,
-							Optional:    true,
-							Description: "Custom notification message body when an alert is triggered. See https://developers.signalfx.com/v2/reference#detector-model for more info",
+							Type:         schema.TypeString,
+							Optional:     true,
+							Description:  "Custom notification message body when an alert is triggered. See https://developers.signalfx.com/v2/reference#detector-model for more info",
+							ValidateFunc: validateParameterizedTemplate,
 						},
 						"parameterized_subject": &schema.Schema{
-							Type:        schema.TypeString,
-							Optional:    true,
-							Description: "Custom notification message subject when an alert is triggered. See https://d    evelopers.signalfx.com/v2/reference#detector-model for more info",
+							Type:         schema.TypeString,
+							Optional:     true,
+							Description:  "Custom notification message subject when an alert is triggered. See https://developers.signalfx.com/v2/reference#detector-model for more info",
+							ValidateFunc: validateParameterizedTemplate,
 						},
 						"runbook_url": &schema.Schema{
-							Type:        schema.TypeString,
-							Optional:    true,
-							Description: "URL of page to consult when an alert is triggered",
+							Type:         schema.TypeString,
+							Optional:     true,
+							Description:  "URL of page to consult when an alert is triggered",
+							ValidateFunc: validateRunbookUrl,
 						},
 						"tip": &schema.Schema{
 							Type:        schema.TypeString,
@@ -174,16 +253,26 @@ func detectorResource() *schema.Resource {
 		Read:   detectorRead,
 		Update: detectorUpdate,
 		Delete: detectorDelete,
+
+		CustomizeDiff: customdiff.All(
+			validateDetectorDelays,
+			validateDetectorNotifications,
+			validateDetectLabels,
+			customdiff.ComputedIf("label_resolutions", func(d *schema.ResourceDiff, meta interface{}) bool {
+				return d.HasChange("program_text")
+			}),
+		),
 	}
 }
 
 /*
   Use Resource object to construct json payload in order to create a detector
 */
-func getPayloadDetector(d *schema.ResourceData) ([]byte, error) {
+func getPayloadDetector(d *schema.ResourceData, config *signalformConfig) ([]byte, error) {
 
 	tf_rules := d.Get("rule").(*schema.Set).List()
 	rules_list := make([]map[string]interface{}, len(tf_rules))
+	tf_severity_notifications := d.Get("severity_notification").([]interface{})
 
 	for i, tf_rule := range tf_rules {
 		tf_rule := tf_rule.(map[string]interface{})
@@ -210,10 +299,16 @@ func getPayloadDetector(d *schema.ResourceData) ([]byte, error) {
 			item["tip"] = val.(string)
 		}
 
-		if notifications, ok := tf_rule["notifications"]; ok {
-			notify := getNotifications(notifications.([]interface{}))
-			item["notifications"] = notify
+		notifications, _ := tf_rule["notifications"].([]interface{})
+		for _, tf_notification_block := range getRuleNotificationBlocks(tf_rule) {
+			notification, err := getNotificationString(tf_notification_block.(map[string]interface{}))
+			if err != nil {
+				return nil, err
+			}
+			notifications = append(notifications, notification)
 		}
+		notifications = append(notifications, getSeverityNotifications(tf_rule["severity"].(string), notifications, tf_severity_notifications)...)
+		item["notifications"] = getNotifications(notifications)
 
 		rules_list[i] = item
 	}
@@ -223,17 +318,23 @@ func getPayloadDetector(d *schema.ResourceData) ([]byte, error) {
 		"description": d.Get("description").(string),
 		"programText": d.Get("program_text").(string),
 		"maxDelay":    nil,
+		"minDelay":    nil,
 		"rules":       rules_list,
 	}
 
-	if val, ok := d.GetOk("max_delay"); ok {
-		payload["maxDelay"] = val.(int) * 1000
+	if maxDelay, ok := getMaxDelay(d.GetOkExists, config); ok {
+		payload["maxDelay"] = maxDelay * 1000
+	}
+	if val, ok := d.GetOk("min_delay"); ok {
+		payload["minDelay"] = val.(int) * 1000
 	}
 
-	if viz := getVisualizationOptionsDetector(d); len(viz) > 0 {
+	if viz := getVisualizationOptionsDetector(d, config); len(viz) > 0 {
 		payload["visualizationOptions"] = viz
 	}
 
+	// Always sent, so that removing the last team from the configuration removes the association
+	payload["teams"] = make([]interface{}, 0)
 	if val, ok := d.GetOk("teams"); ok {
 		payload["teams"] = val.([]interface{})
 	}
@@ -242,10 +343,12 @@ func getPayloadDetector(d *schema.ResourceData) ([]byte, error) {
 		payload["tags"] = val.([]interface{})
 	}
 
+	payload["authorizedWriters"] = getAuthorizedWriters(d)
+
 	return json.Marshal(payload)
 }
 
-func getVisualizationOptionsDetector(d *schema.ResourceData) map[string]interface{} {
+func getVisualizationOptionsDetector(d *schema.ResourceData, config *signalformConfig) map[string]interface{} {
 	viz := make(map[string]interface{})
 	if val, ok := d.GetOk("show_data_markers"); ok {
 		viz["showDataMarkers"] = val.(bool)
@@ -253,13 +356,12 @@ func getVisualizationOptionsDetector(d *schema.ResourceData) map[string]interfac
 	if val, ok := d.GetOk("show_event_lines"); ok {
 		viz["showEventLines"] = val.(bool)
 	}
-	if val, ok := d.GetOk("disable_sampling"); ok {
-		viz["disableSampling"] = val.(bool)
-	}
+	// Always sent, so that switching it back to false is explicit in the payload
+	viz["disableSampling"] = getDisableSampling(d, config)
 
 	timeMap := make(map[string]interface{})
 	if val, ok := d.GetOk("time_range"); ok {
-		if ms, err := fromRangeToMilliSeconds(val.(string)); err == nil {
+		if ms, err := sfxtime.ToMilliseconds(val.(string)); err == nil {
 			timeMap["range"] = ms
 			timeMap["type"] = "relative"
 		}
@@ -307,39 +409,473 @@ func getNotifications(tf_notifications []interface{}) []map[string]interface{} {
 	return notifications_list
 }
 
+/*
+  Fields of a structured notification, serialized by getNotificationString
+*/
+func notificationSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"type": &schema.Schema{
+			Type:         schema.TypeString,
+			Required:     true,
+			ValidateFunc: validateNotificationType,
+			Description:  "Type of the notification. Must be one of: Email, PagerDuty, Slack, Webhook, Team, TeamEmail",
+		},
+		"email": &schema.Schema{
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "Email address to notify. Required by the Email type",
+		},
+		"credential_id": &schema.Schema{
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "ID of the integration to notify through. Required by the PagerDuty and Slack types",
+		},
+		"channel": &schema.Schema{
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "Slack channel to notify, without the leading #. Required by the Slack type",
+		},
+		"secret": &schema.Schema{
+			Type:        schema.TypeString,
+			Optional:    true,
+			Sensitive:   true,
+			Description: "Secret sent in the X-SFX-Webhook-Secret header of the Webhook type",
+		},
+		"url": &schema.Schema{
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "URL to call. Required by the Webhook type",
+		},
+		"team": &schema.Schema{
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "ID of the team to notify. Required by the Team and TeamEmail types",
+		},
+	}
+}
+
+/*
+  Number of comma separated fields of the notification strings of each type, including the type
+*/
+var NotificationFieldCounts = map[string]int{
+	"Email":     2,
+	"PagerDuty": 2,
+	"Slack":     3,
+	"Webhook":   3,
+	"Team":      2,
+	"TeamEmail": 2,
+}
+
+/*
+  Serializes a notification block into the notification string format, failing if a field required by its type is missing.
+  The string is returned even then, so that callers can tell unknown fields apart.
+*/
+func getNotificationString(tf_notification map[string]interface{}) (string, error) {
+	notificationType := tf_notification["type"].(string)
+	field := func(name string) string {
+		value, _ := tf_notification[name].(string)
+		return value
+	}
+	var fields []string
+	switch notificationType {
+	case "Email":
+		fields = []string{field("email")}
+	case "PagerDuty":
+		fields = []string{field("credential_id")}
+	case "Slack":
+		fields = []string{field("credential_id"), field("channel")}
+	case "Webhook":
+		fields = []string{field("secret"), field("url")}
+	case "Team", "TeamEmail":
+		fields = []string{field("team")}
+	}
+	notification := strings.Join(append([]string{notificationType}, fields...), ",")
+	if _, errors := validateNotification(notification, "notification"); len(errors) > 0 {
+		return notification, errors[0]
+	}
+	return notification, nil
+}
+
+/*
+  Validates a notification string, checking the fields required by its type
+*/
+func validateNotification(v interface{}, k string) (we []string, errors []error) {
+	value := v.(string)
+	vars := strings.Split(value, ",")
+	count, ok := NotificationFieldCounts[vars[0]]
+	if !ok {
+		_, errors = validateNotificationType(vars[0], k)
+		return
+	}
+	if len(vars) != count {
+		errors = append(errors, fmt.Errorf("%s not allowed; %s notifications must have %d comma separated fields", value, vars[0], count))
+		return
+	}
+	for i, required := range vars {
+		// The secret of webhooks is optional
+		if required == "" && !(vars[0] == "Webhook" && i == 1) {
+			errors = append(errors, fmt.Errorf("%s not allowed; field %d of %s notifications must not be empty", value, i+1, vars[0]))
+			return
+		}
+	}
+	if vars[0] == "Email" && !emailRegexp.MatchString(vars[1]) {
+		errors = append(errors, fmt.Errorf("%s not allowed; %s is not a valid email address", value, vars[1]))
+	}
+	return
+}
+
+func validateNotificationType(v interface{}, k string) (we []string, errors []error) {
+	value := v.(string)
+	allowedWords := []string{"Email", "PagerDuty", "Slack", "Webhook", "Team", "TeamEmail"}
+	for _, word := range allowedWords {
+		if value == word {
+			return
+		}
+	}
+	errors = append(errors, fmt.Errorf("%s not allowed; must be one of: %s", value, strings.Join(allowedWords, ", ")))
+	return
+}
+
+func validateMuteDuration(v interface{}, k string) (we []string, errors []error) {
+	value := v.(int)
+	if value < 1 || value > 3600 {
+		errors = append(errors, fmt.Errorf("%d not allowed; %s must be >= 1 && <= 3600", value, k))
+	}
+	return
+}
+
+/*
+  Checks at plan time that the detect label of every rule is published by the program text, which the
+  API only reports at apply time
+*/
+func validateDetectLabels(diff *schema.ResourceDiff, meta interface{}) error {
+	if !diff.NewValueKnown("program_text") {
+		return nil
+	}
+	labels, ok := getPublishLabels(diff.Get("program_text").(string))
+	if !ok {
+		return nil
+	}
+	for _, tf_rule := range diff.Get("rule").(*schema.Set).List() {
+		label := tf_rule.(map[string]interface{})["detect_label"].(string)
+		if !labels[label] && !strings.Contains(label, hcl2shim.UnknownVariableValue) {
+			published := make([]string, 0)
+			for published_label := range labels {
+				published = append(published, published_label)
+			}
+			sort.Strings(published)
+			return fmt.Errorf("Rule %s: the program text of %s publishes no such label; published labels: %s", label, diff.Get("name"), strings.Join(published, ", "))
+		}
+	}
+	return nil
+}
+
+/*
+  Returns the labels published by a program text. ok is false when some label is not a string literal
+  (e.g. a variable), in which case the labels cannot be known before running the program.
+*/
+func getPublishLabels(programText string) (labels map[string]bool, ok bool) {
+	labels = make(map[string]bool)
+	matches := publishLabelRegexp.FindAllStringSubmatch(programText, -1)
+	for _, match := range matches {
+		labels[match[1]+match[2]] = true
+	}
+	return labels, len(matches) == len(publishCallRegexp.FindAllString(programText, -1))
+}
+
+/*
+  SignalFx waits at least min_delay and at most max_delay for late datapoints, so a min_delay above the
+  max_delay sent, set on the detector or coming from the provider default, can never be honored
+*/
+func validateDetectorDelays(diff *schema.ResourceDiff, meta interface{}) error {
+	config, ok := meta.(*signalformConfig)
+	if !ok || !diff.NewValueKnown("min_delay") || !diff.NewValueKnown("max_delay") {
+		return nil
+	}
+	maxDelay, _ := getMaxDelay(diff.GetOkExists, config)
+	return checkDetectorDelays(diff.Get("min_delay").(int), maxDelay)
+}
+
+func checkDetectorDelays(minDelay int, maxDelay int) error {
+	if maxDelay > 0 && minDelay > maxDelay {
+		return fmt.Errorf("min_delay (%ds) must not be greater than max_delay (%ds)", minDelay, maxDelay)
+	}
+	return nil
+}
+
+/*
+  Checks the notification blocks of the rules at plan time, since the fields they require depend on their type.
+  When validate_notification_credentials is set, the teams and integrations referenced by the notifications of
+  every rule are also checked, each once per detector.
+*/
+func validateDetectorNotifications(diff *schema.ResourceDiff, meta interface{}) error {
+	tf_severity_notifications, _ := diff.Get("severity_notification").([]interface{})
+	notifications, err := getDetectorNotifications(diff.Get("rule").(*schema.Set).List(), tf_severity_notifications)
+	if err != nil {
+		return err
+	}
+
+	config, ok := meta.(*signalformConfig)
+	if !ok || !config.ValidateNotificationCredentials {
+		return nil
+	}
+	for _, teamId := range getNotificationTeamIds(notifications) {
+		if _, err := getSignalFxObject(fmt.Sprintf("%s/%s", TEAM_API_URL, teamId), config); err != nil {
+			return fmt.Errorf("Detector %s: team %s: %s", diff.Get("name"), teamId, err.Error())
+		}
+	}
+	for _, notification := range getNotificationCredentials(notifications) {
+		if _, err := resolveNotificationTarget(notification, config); err != nil {
+			return fmt.Errorf("Detector %s: %s: %s", diff.Get("name"), notification, err.Error())
+		}
+	}
+	return nil
+}
+
+/*
+  Returns the notification blocks of a rule, which are a set
+*/
+func getRuleNotificationBlocks(tf_rule map[string]interface{}) []interface{} {
+	if blocks, ok := tf_rule["notification"].(*schema.Set); ok {
+		return blocks.List()
+	}
+	return nil
+}
+
+/*
+  Returns the notification strings of every rule of a detector and of its severity_notification blocks
+*/
+func getDetectorNotifications(tf_rules []interface{}, tf_severity_notifications []interface{}) ([]interface{}, error) {
+	notifications := make([]interface{}, 0)
+	for _, tf_rule := range tf_rules {
+		tf_rule := tf_rule.(map[string]interface{})
+		tf_notifications, _ := tf_rule["notifications"].([]interface{})
+		notifications = append(notifications, tf_notifications...)
+		for _, tf_notification_block := range getRuleNotificationBlocks(tf_rule) {
+			notification, err := getNotificationString(tf_notification_block.(map[string]interface{}))
+			// Fields computed from other resources are only known at apply time
+			if err != nil && !strings.Contains(notification, hcl2shim.UnknownVariableValue) {
+				return nil, fmt.Errorf("Rule %s: %s", tf_rule["detect_label"], err.Error())
+			}
+			notifications = append(notifications, notification)
+		}
+	}
+	for _, tf_severity_notification := range tf_severity_notifications {
+		notifications = append(notifications, tf_severity_notification.(map[string]interface{})["notification"])
+	}
+	return notifications, nil
+}
+
+/*
+  Returns the PagerDuty and Slack notification strings, whose credential IDs reference integrations,
+  skipping the ones not known yet
+*/
+func getNotificationCredentials(notifications []interface{}) []string {
+	seen := map[string]bool{}
+	credentials := make([]string, 0)
+	for _, notification := range notifications {
+		notification, _ := notification.(string)
+		vars := strings.Split(notification, ",")
+		if vars[0] != "PagerDuty" && vars[0] != "Slack" {
+			continue
+		}
+		if strings.Contains(notification, hcl2shim.UnknownVariableValue) || seen[notification] {
+			continue
+		}
+		seen[notification] = true
+		credentials = append(credentials, notification)
+	}
+	return credentials
+}
+
+/*
+  Returns the IDs of the teams referenced by Team and TeamEmail notification strings, skipping the ones
+  not known yet
+*/
+func getNotificationTeamIds(notifications []interface{}) []string {
+	seen := map[string]bool{}
+	teamIds := make([]string, 0)
+	for _, notification := range notifications {
+		notification, _ := notification.(string)
+		vars := strings.Split(notification, ",")
+		if len(vars) != 2 || (vars[0] != "Team" && vars[0] != "TeamEmail") {
+			continue
+		}
+		if vars[1] == "" || strings.Contains(vars[1], hcl2shim.UnknownVariableValue) || seen[vars[1]] {
+			continue
+		}
+		seen[vars[1]] = true
+		teamIds = append(teamIds, vars[1])
+	}
+	return teamIds
+}
+
+/*
+  Severities ordered from the lowest to the highest
+*/
+var SeverityRanks = map[string]int{
+	"Info":     0,
+	"Warning":  1,
+	"Minor":    2,
+	"Major":    3,
+	"Critical": 4,
+}
+
+/*
+  Returns the severity_notification entries applying to a rule of the given severity, skipping those
+  already listed in the rule notifications.
+*/
+func getSeverityNotifications(severity string, ruleNotifications []interface{}, tf_severity_notifications []interface{}) []interface{} {
+	notifications := make([]interface{}, 0)
+	seen := make(map[string]bool)
+	for _, notification := range ruleNotifications {
+		seen[notification.(string)] = true
+	}
+	for _, tf_severity_notification := range tf_severity_notifications {
+		tf_severity_notification := tf_severity_notification.(map[string]interface{})
+		notification := tf_severity_notification["notification"].(string)
+		if SeverityRanks[severity] < SeverityRanks[tf_severity_notification["min_severity"].(string)] || seen[notification] {
+			continue
+		}
+		seen[notification] = true
+		notifications = append(notifications, notification)
+	}
+	return notifications
+}
+
 func detectorCreate(d *schema.ResourceData, meta interface{}) error {
 	config := meta.(*signalformConfig)
-	payload, err := getPayloadDetector(d)
+	payload, err := getPayloadDetector(d, config)
 	if err != nil {
 		return fmt.Errorf("Failed creating json payload: %s", err.Error())
 	}
 
-	return resourceCreate(DETECTOR_API_URL, config.AuthToken, payload, d)
+	if err := resourceCreate(DETECTOR_API_URL, config, payload, d); err != nil {
+		return err
+	}
+	// Reads back the values computed by SignalFx, e.g. the label resolutions
+	return detectorRead(d, meta)
 }
 
 func detectorRead(d *schema.ResourceData, meta interface{}) error {
 	config := meta.(*signalformConfig)
 	url := fmt.Sprintf("%s/%s", DETECTOR_API_URL, d.Id())
 
-	return resourceRead(url, config.AuthToken, d)
+	return resourceReadWithState(url, config, d, func(detector map[string]interface{}, d *schema.ResourceData) error {
+		return detectorAPIToState(detector, d, config)
+	})
+}
+
+/*
+  Reflects the program options and the label resolutions returned by the API in the state. Unset (null)
+  values are mapped to the schema zero values, so that detectors which never set them don't show
+  perpetual diffs. Program options matching the provider defaults are only set when the state holds them.
+*/
+func detectorAPIToState(detector map[string]interface{}, d *schema.ResourceData, config *signalformConfig) error {
+	maxDelay := 0
+	if val, ok := detector["maxDelay"].(float64); ok {
+		maxDelay = int(val) / 1000
+	}
+	// A value coming from the provider default stays unset, so that updates keep following the default
+	if _, ok := d.GetOkExists("max_delay"); ok || maxDelay != config.DefaultMaxDelay {
+		if err := d.Set("max_delay", maxDelay); err != nil {
+			return err
+		}
+	}
+
+	minDelay := 0
+	if val, ok := detector["minDelay"].(float64); ok {
+		minDelay = int(val) / 1000
+	}
+	if err := d.Set("min_delay", minDelay); err != nil {
+		return err
+	}
+
+	disableSampling := false
+	if viz, ok := detector["visualizationOptions"].(map[string]interface{}); ok {
+		if val, ok := viz["disableSampling"].(bool); ok {
+			disableSampling = val
+		}
+	}
+	if _, ok := d.GetOkExists("disable_sampling"); ok || disableSampling != config.DefaultDisableSampling {
+		if err := d.Set("disable_sampling", disableSampling); err != nil {
+			return err
+		}
+	}
+
+	labelResolutions := make(map[string]interface{})
+	if resolutions, ok := detector["labelResolutions"].(map[string]interface{}); ok {
+		for label, resolution := range resolutions {
+			if val, ok := resolution.(float64); ok {
+				labelResolutions[label] = int(val)
+			}
+		}
+	}
+	return d.Set("label_resolutions", labelResolutions)
 }
 
 func detectorUpdate(d *schema.ResourceData, meta interface{}) error {
 	config := meta.(*signalformConfig)
-	payload, err := getPayloadDetector(d)
+	payload, err := getPayloadDetector(d, config)
 	if err != nil {
 		return fmt.Errorf("Failed creating json payload: %s", err.Error())
 	}
 	url := fmt.Sprintf("%s/%s", DETECTOR_API_URL, d.Id())
 
-	return resourceUpdate(url, config.AuthToken, payload, d)
+	if d.Get("mute_during_update").(bool) {
+		if err := muteDetector(d, config); err != nil {
+			return err
+		}
+	}
+	if err := resourceUpdate(url, config, payload, d); err != nil {
+		return err
+	}
+	return detectorRead(d, meta)
+}
+
+/*
+  Creates a muting rule covering the alerts of the detector, starting now. It expires by itself, so that
+  the alerts fired while SignalFx re-evaluates the updated program are not notified.
+*/
+func muteDetector(d *schema.ResourceData, config *signalformConfig) error {
+	payload, err := getPayloadUpdateMutingRule(d.Id(), d.Get("name").(string), d.Get("mute_during_update_duration").(int), time.Now())
+	if err != nil {
+		return fmt.Errorf("Failed creating json payload: %s", err.Error())
+	}
+	status_code, resp_body, err := sendRequest("POST", ALERT_MUTING_API_URL, config, payload)
+	if err != nil {
+		return err
+	}
+	if status_code != 200 {
+		return fmt.Errorf("Muting the detector %s before its update, SignalFx returned status %d: \n%s", d.Get("name"), status_code, resp_body)
+	}
+	return nil
+}
+
+func getPayloadUpdateMutingRule(detectorId string, name string, duration int, now time.Time) ([]byte, error) {
+	start := now.UnixNano() / int64(time.Millisecond)
+	return json.Marshal(map[string]interface{}{
+		"description": fmt.Sprintf("Signalform update of the detector %s", name),
+		"startTime":   start,
+		"stopTime":    start + int64(duration)*1000,
+		"filters": []map[string]interface{}{
+			map[string]interface{}{
+				"property":      "sf_detectorId",
+				"propertyValue": detectorId,
+				"NOT":           false,
+			},
+		},
+	})
 }
 
 func detectorDelete(d *schema.ResourceData, meta interface{}) error {
+	if err := checkDeletionProtection(d); err != nil {
+		return err
+	}
 	config := meta.(*signalformConfig)
 	url := fmt.Sprintf("%s/%s", DETECTOR_API_URL, d.Id())
 
-	return resourceDelete(url, config.AuthToken, d)
+	return resourceDelete(url, config, d)
 }
 
 /*
@@ -376,17 +912,74 @@ func resourceRuleHash(v interface{}) int {
 		}
 	}
 
+	// Same for the notification blocks, whose order does not matter either
+	if _, ok := m["notification"]; ok {
+		s_notifications := make([]string, 0)
+		for _, tf_notification := range getRuleNotificationBlocks(m) {
+			notification, _ := getNotificationString(tf_notification.(map[string]interface{}))
+			s_notifications = append(s_notifications, notification)
+		}
+		sort.Strings(s_notifications)
+
+		for _, notification := range s_notifications {
+			buf.WriteString(fmt.Sprintf("%s-", notification))
+		}
+	}
+
 	return hashcode.String(buf.String())
 }
 
+/*
+  Validates that every {{ of a custom notification message template is closed, since SignalFx would
+  otherwise send the template as is
+*/
+func validateParameterizedTemplate(v interface{}, k string) (we []string, errors []error) {
+	value := v.(string)
+	for rest := value; ; {
+		start := strings.Index(rest, "{{")
+		if start == -1 {
+			return
+		}
+		end := strings.Index(rest[start+2:], "}}")
+		if end == -1 || strings.Contains(rest[start+2:start+2+end], "{{") {
+			errors = append(errors, fmt.Errorf("%s has an unclosed {{ at offset %d", k, len(value)-len(rest)+start))
+			return
+		}
+		rest = rest[start+2+end+2:]
+	}
+}
+
+/*
+  Validates that runbook_url is an absolute http(s) URL, since SignalFx links to it from the alerts
+*/
+func validateRunbookUrl(v interface{}, k string) (we []string, errors []error) {
+	value := v.(string)
+	if u, err := url.Parse(value); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errors = append(errors, fmt.Errorf("%s not allowed; %s must be an absolute http or https URL", value, k))
+	}
+	return
+}
+
 /*
   Validates the severity field against a list of allowed words.
 */
 func validateSeverity(v interface{}, k string) (we []string, errors []error) {
 	value := v.(string)
-	allowedWords := []string{"Critical", "Warning", "Major", "Minor", "Info"}
+	if _, ok := SeverityRanks[value]; ok {
+		return
+	}
+	// From the highest to the lowest
+	allowedWords := make([]string, 0, len(SeverityRanks))
+	for word := range SeverityRanks {
+		allowedWords = append(allowedWords, word)
+	}
+	sort.Slice(allowedWords, func(i, j int) bool {
+		return SeverityRanks[allowedWords[i]] > SeverityRanks[allowedWords[j]]
+	})
 	for _, word := range allowedWords {
-		if value == word {
+		// Severities are case sensitive
+		if strings.EqualFold(value, word) {
+			errors = append(errors, fmt.Errorf("%s not allowed; did you mean %s?", value, word))
 			return
 		}
 	}
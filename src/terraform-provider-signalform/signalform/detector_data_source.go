@@ -0,0 +1,97 @@
+package signalform
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+/*
+  Data source looking up a detector by name and/or tags, so that muting rules and links can reference
+  detectors owned by other teams
+*/
+func detectorDataSource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "Name of the detector",
+			},
+			"tags": &schema.Schema{
+				Type:        schema.TypeList,
+				Optional:    true,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Tags the detector must have",
+			},
+			"description": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Description of the detector",
+			},
+			"url": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "URL of the detector",
+			},
+		},
+
+		Read: detectorDataSourceRead,
+	}
+}
+
+func detectorDataSourceRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*signalformConfig)
+	name := d.Get("name").(string)
+	tags := make([]string, 0)
+	for _, tag := range d.Get("tags").([]interface{}) {
+		tags = append(tags, tag.(string))
+	}
+	if name == "" && len(tags) == 0 {
+		return fmt.Errorf("One of name or tags is required")
+	}
+
+	query := url.Values{}
+	if name != "" {
+		query.Set("name", name)
+	}
+	for _, tag := range tags {
+		query.Add("tags", tag)
+	}
+	detectors, err := searchSignalFxObjects(DETECTOR_API_URL, query, config)
+	if err != nil {
+		return fmt.Errorf("Searching detectors: %s", err.Error())
+	}
+	detector, err := findDetector(detectors, name, tags)
+	if err != nil {
+		return err
+	}
+
+	id, _ := detector["id"].(string)
+	d.SetId(id)
+	d.Set("name", detector["name"])
+	d.Set("tags", detector["tags"])
+	d.Set("description", detector["description"])
+	return d.Set("url", getResourceUrl(DETECTOR_URL, id, config))
+}
+
+/*
+  Keeps the detectors having exactly the name, if set, and all the tags, which must leave a single one
+*/
+func findDetector(detectors []map[string]interface{}, name string, tags []string) (map[string]interface{}, error) {
+	tagged := filterObjectsByTags(detectors, tags)
+	if name != "" {
+		return findObjectByName("detector", tagged, name)
+	}
+	if len(tagged) != 1 {
+		ids := make([]interface{}, len(tagged))
+		for i, detector := range tagged {
+			ids[i] = detector["id"]
+		}
+		return nil, fmt.Errorf("%d detectors have the tags %v, instead of one: %v", len(tagged), tags, ids)
+	}
+	return tagged[0], nil
+}
@@ -0,0 +1,28 @@
+package signalform
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFindDetector(t *testing.T) {
+	detectors := []map[string]interface{}{
+		map[string]interface{}{"id": "D1", "name": "API latency", "tags": []interface{}{"api", "prod"}},
+		map[string]interface{}{"id": "D2", "name": "API latency", "tags": []interface{}{"api", "staging"}},
+		map[string]interface{}{"id": "D3", "name": "API errors", "tags": []interface{}{"api", "prod"}},
+	}
+
+	detector, err := findDetector(detectors, "API latency", []string{"prod"})
+	assert.Nil(t, err)
+	assert.Equal(t, "D1", detector["id"])
+
+	_, err = findDetector(detectors, "API latency", []string{})
+	assert.Contains(t, err.Error(), "2 detectors are named API latency")
+
+	detector, err = findDetector(detectors, "", []string{"staging"})
+	assert.Nil(t, err)
+	assert.Equal(t, "D2", detector["id"])
+
+	_, err = findDetector(detectors, "", []string{"api", "prod"})
+	assert.Contains(t, err.Error(), "2 detectors have the tags [api prod]")
+}
@@ -1,9 +1,13 @@
 package signalform
 
 import (
+	"encoding/json"
 	"github.com/hashicorp/terraform/helper/hashcode"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
 )
 
 func TestGetNotifications(t *testing.T) {
@@ -71,6 +75,66 @@ func TestResourceRuleHash(t *testing.T) {
 	assert.Equal(t, expected, resourceRuleHash(values))
 }
 
+func TestResourceRuleHashNotificationOrder(t *testing.T) {
+	rule := func(notifications []interface{}, blocks []interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"description":   "Test Rule Name",
+			"detect_label":  "Test Detect Label",
+			"severity":      "Critical",
+			"disabled":      false,
+			"notifications": notifications,
+			"notification":  notificationBlocks(blocks...),
+		}
+	}
+	email := map[string]interface{}{"type": "Email", "email": "test@yelp.com"}
+	team := map[string]interface{}{"type": "Team", "team": "teamId"}
+
+	assert.Equal(t,
+		resourceRuleHash(rule([]interface{}{"PagerDuty,credId", "Email,test@yelp.com"}, []interface{}{email, team})),
+		resourceRuleHash(rule([]interface{}{"Email,test@yelp.com", "PagerDuty,credId"}, []interface{}{team, email})))
+	assert.NotEqual(t,
+		resourceRuleHash(rule([]interface{}{"PagerDuty,credId"}, []interface{}{email})),
+		resourceRuleHash(rule([]interface{}{"PagerDuty,credId"}, []interface{}{team})))
+}
+
+func notificationBlocks(blocks ...interface{}) *schema.Set {
+	return schema.NewSet(schema.HashResource(&schema.Resource{Schema: notificationSchema()}), blocks)
+}
+
+func TestDetectorNotificationOrderDiff(t *testing.T) {
+	config := func(blocks ...interface{}) *terraform.ResourceConfig {
+		return terraform.NewResourceConfigRaw(map[string]interface{}{
+			"name":         "Latency",
+			"program_text": "detect(when(data('app.latency') > 100)).publish('high')",
+			"rule": []interface{}{
+				map[string]interface{}{
+					"detect_label": "high",
+					"severity":     "Critical",
+					"notification": blocks,
+				},
+			},
+		})
+	}
+	email := map[string]interface{}{"type": "Email", "email": "test@yelp.com"}
+	team := map[string]interface{}{"type": "Team", "team": "teamId"}
+	resource := detectorResource()
+
+	diff, err := resource.Diff(nil, config(email, team), nil)
+	assert.Nil(t, err)
+	state := &terraform.InstanceState{ID: "id", Attributes: map[string]string{}}
+	for key, attribute := range diff.Attributes {
+		state.Attributes[key] = attribute.New
+	}
+
+	diff, err = resource.Diff(state, config(team, email), nil)
+	assert.Nil(t, err)
+	assert.True(t, diff.Empty(), "%v", diff)
+
+	diff, err = resource.Diff(state, config(team), nil)
+	assert.Nil(t, err)
+	assert.False(t, diff.Empty())
+}
+
 func TestValidateSeverityAllowed(t *testing.T) {
 	_, errors := validateSeverity("Critical", "severity")
 	assert.Equal(t, len(errors), 0)
@@ -80,3 +144,275 @@ func TestValidateSeverityNotAllowed(t *testing.T) {
 	_, errors := validateSeverity("foo", "severity")
 	assert.Equal(t, len(errors), 1)
 }
+
+func TestValidateSeverityMessages(t *testing.T) {
+	_, errors := validateSeverity("critical", "severity")
+	assert.Equal(t, "critical not allowed; did you mean Critical?", errors[0].Error())
+	_, errors = validateSeverity("High", "severity")
+	assert.Equal(t, "High not allowed; must be one of: Critical, Major, Minor, Warning, Info", errors[0].Error())
+}
+
+func TestDetectorAPIToState(t *testing.T) {
+	d := detectorResource().TestResourceData()
+	detector := map[string]interface{}{
+		"maxDelay": float64(30000),
+		"minDelay": nil,
+		"visualizationOptions": map[string]interface{}{
+			"disableSampling": true,
+		},
+		"labelResolutions": map[string]interface{}{
+			"high": float64(10000),
+		},
+	}
+
+	assert.Nil(t, detectorAPIToState(detector, d, &signalformConfig{}))
+	assert.Equal(t, 30, d.Get("max_delay"))
+	assert.Equal(t, 0, d.Get("min_delay"))
+	assert.Equal(t, true, d.Get("disable_sampling"))
+	assert.Equal(t, map[string]interface{}{"high": 10000}, d.Get("label_resolutions"))
+}
+
+func TestDetectorAPIToStateDefaults(t *testing.T) {
+	d := detectorResource().TestResourceData()
+
+	assert.Nil(t, detectorAPIToState(map[string]interface{}{}, d, &signalformConfig{}))
+	assert.Equal(t, 0, d.Get("max_delay"))
+	assert.Equal(t, 0, d.Get("min_delay"))
+	assert.Equal(t, false, d.Get("disable_sampling"))
+	assert.Equal(t, map[string]interface{}{}, d.Get("label_resolutions"))
+}
+
+func TestDetectorAPIToStateProviderDefaults(t *testing.T) {
+	config := &signalformConfig{DefaultMaxDelay: 300, DefaultDisableSampling: true}
+	d := detectorResource().TestResourceData()
+	detector := map[string]interface{}{
+		"maxDelay": float64(300000),
+		"visualizationOptions": map[string]interface{}{
+			"disableSampling": true,
+		},
+	}
+
+	assert.Nil(t, detectorAPIToState(detector, d, config))
+	assert.Equal(t, 0, d.Get("max_delay"))
+	assert.Equal(t, false, d.Get("disable_sampling"))
+
+	detector["maxDelay"] = float64(60000)
+	assert.Nil(t, detectorAPIToState(detector, d, config))
+	assert.Equal(t, 60, d.Get("max_delay"))
+
+	// Values set in the state are read back, even when they match the provider defaults
+	d = detectorResource().TestResourceData()
+	d.Set("max_delay", 300)
+	d.Set("disable_sampling", false)
+	detector["maxDelay"] = float64(300000)
+	assert.Nil(t, detectorAPIToState(detector, d, config))
+	assert.Equal(t, 300, d.Get("max_delay"))
+	assert.Equal(t, true, d.Get("disable_sampling"))
+}
+
+func TestGetVisualizationOptionsDetectorDisableSampling(t *testing.T) {
+	d := detectorResource().TestResourceData()
+	assert.Equal(t, false, getVisualizationOptionsDetector(d, &signalformConfig{})["disableSampling"])
+
+	d.Set("disable_sampling", true)
+	assert.Equal(t, true, getVisualizationOptionsDetector(d, &signalformConfig{})["disableSampling"])
+}
+
+func TestGetSeverityNotifications(t *testing.T) {
+	severityNotifications := []interface{}{
+		map[string]interface{}{
+			"notification": "PagerDuty,credId",
+			"min_severity": "Major",
+		},
+		map[string]interface{}{
+			"notification": "Email,test@yelp.com",
+			"min_severity": "Info",
+		},
+	}
+
+	assert.Equal(t, []interface{}{"PagerDuty,credId", "Email,test@yelp.com"}, getSeverityNotifications("Critical", []interface{}{}, severityNotifications))
+	assert.Equal(t, []interface{}{"PagerDuty,credId"}, getSeverityNotifications("Major", []interface{}{"Email,test@yelp.com"}, severityNotifications))
+	assert.Equal(t, []interface{}{"Email,test@yelp.com"}, getSeverityNotifications("Warning", nil, severityNotifications))
+}
+
+func TestGetNotificationString(t *testing.T) {
+	notification, err := getNotificationString(map[string]interface{}{"type": "Slack", "credential_id": "credId", "channel": "alerts"})
+	assert.Nil(t, err)
+	assert.Equal(t, "Slack,credId,alerts", notification)
+
+	notification, err = getNotificationString(map[string]interface{}{"type": "Webhook", "secret": "", "url": "https://foo.bar.com"})
+	assert.Nil(t, err)
+	assert.Equal(t, "Webhook,,https://foo.bar.com", notification)
+
+	_, err = getNotificationString(map[string]interface{}{"type": "Slack", "credential_id": "credId", "channel": ""})
+	assert.NotNil(t, err)
+}
+
+func TestValidateNotification(t *testing.T) {
+	for _, value := range []string{"Email,test@yelp.com", "PagerDuty,credId", "Slack,credId,alerts", "Webhook,,https://foo.bar.com", "Team,teamId", "TeamEmail,teamId"} {
+		_, errors := validateNotification(value, "notifications")
+		assert.Equal(t, 0, len(errors), value)
+	}
+	for _, value := range []string{"Email,test", "PagerDuty", "Slack,credId", "Webhook,secret,", "Pager,credId", "Team,"} {
+		_, errors := validateNotification(value, "notifications")
+		assert.Equal(t, 1, len(errors), value)
+	}
+}
+
+func TestGetNotificationTeamIds(t *testing.T) {
+	notifications := []interface{}{
+		"Team,teamA",
+		"Email,test@yelp.com",
+		"TeamEmail,teamB",
+		"TeamEmail,teamA",
+		"Team,74D93920-ED26-11E3-AC10-0800200C9A66",
+	}
+	assert.Equal(t, []string{"teamA", "teamB"}, getNotificationTeamIds(notifications))
+	assert.Equal(t, []string{}, getNotificationTeamIds(nil))
+}
+
+func TestGetDetectorNotifications(t *testing.T) {
+	rules := []interface{}{
+		map[string]interface{}{
+			"detect_label":  "high",
+			"notifications": []interface{}{"Team,teamA"},
+		},
+		map[string]interface{}{
+			"detect_label": "low",
+			"notification": notificationBlocks(
+				map[string]interface{}{"type": "Team", "team": "teamA"},
+			),
+		},
+	}
+	severityNotifications := []interface{}{
+		map[string]interface{}{"severity": "Critical", "notification": "TeamEmail,teamA"},
+	}
+	notifications, err := getDetectorNotifications(rules, severityNotifications)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{"Team,teamA", "Team,teamA", "TeamEmail,teamA"}, notifications)
+	// The team of every rule is checked once per detector
+	assert.Equal(t, []string{"teamA"}, getNotificationTeamIds(notifications))
+
+	_, err = getDetectorNotifications([]interface{}{
+		map[string]interface{}{
+			"detect_label": "high",
+			"notification": notificationBlocks(
+				map[string]interface{}{"type": "Team", "team": ""},
+			),
+		},
+	}, nil)
+	assert.Contains(t, err.Error(), "Rule high: ")
+}
+
+func TestValidateRunbookUrl(t *testing.T) {
+	_, errors := validateRunbookUrl("https://wiki.yelp.com/runbooks/app-delay", "runbook_url")
+	assert.Equal(t, 0, len(errors))
+	for _, value := range []string{"wiki/runbooks", "ftp://wiki.yelp.com/runbook", "https://"} {
+		_, errors := validateRunbookUrl(value, "runbook_url")
+		assert.Equal(t, 1, len(errors), value)
+	}
+}
+
+func TestValidateParameterizedTemplate(t *testing.T) {
+	for _, value := range []string{"", "no variables", "{{ruleName}} on {{dimensions.host}}", "{{#if anomalous}}Triggered{{else}}Cleared{{/if}}"} {
+		_, errors := validateParameterizedTemplate(value, "parameterized_body")
+		assert.Equal(t, 0, len(errors), value)
+	}
+	for _, value := range []string{"{{ruleName", "{{ruleName {{dimensions}}", "{{a}} {{"} {
+		_, errors := validateParameterizedTemplate(value, "parameterized_body")
+		assert.Equal(t, 1, len(errors), value)
+	}
+}
+
+func TestCheckDetectorDelays(t *testing.T) {
+	assert.Nil(t, checkDetectorDelays(0, 0))
+	assert.Nil(t, checkDetectorDelays(30, 0))
+	assert.Nil(t, checkDetectorDelays(30, 60))
+	assert.NotNil(t, checkDetectorDelays(90, 60))
+}
+
+func TestCheckDetectorDelaysProviderDefault(t *testing.T) {
+	config := &signalformConfig{DefaultMaxDelay: 60}
+	d := detectorResource().TestResourceData()
+
+	// The provider default is the max_delay sent
+	maxDelay, _ := getMaxDelay(d.GetOkExists, config)
+	assert.NotNil(t, checkDetectorDelays(90, maxDelay))
+
+	// An explicit 0 is the automatic delay, which min_delay does not conflict with
+	d.Set("max_delay", 0)
+	maxDelay, _ = getMaxDelay(d.GetOkExists, config)
+	assert.Nil(t, checkDetectorDelays(90, maxDelay))
+}
+
+func TestGetPayloadDetectorTeams(t *testing.T) {
+	d := detectorResource().TestResourceData()
+	d.Set("name", "detector")
+	d.Set("program_text", "detect(when(data('cpu.utilization') > 90)).publish('high')")
+
+	payload, err := getPayloadDetector(d, &signalformConfig{})
+	assert.Nil(t, err)
+	decoded := map[string]interface{}{}
+	json.Unmarshal(payload, &decoded)
+	assert.Equal(t, []interface{}{}, decoded["teams"])
+
+	d.Set("teams", []interface{}{"team1"})
+	payload, err = getPayloadDetector(d, &signalformConfig{})
+	assert.Nil(t, err)
+	json.Unmarshal(payload, &decoded)
+	assert.Equal(t, []interface{}{"team1"}, decoded["teams"])
+}
+
+func TestGetPayloadDetectorDisabledRule(t *testing.T) {
+	d := detectorResource().TestResourceData()
+	d.Set("name", "detector")
+	d.Set("program_text", "detect(when(data('cpu.utilization') > 90)).publish('high')")
+	d.Set("rule", []interface{}{
+		map[string]interface{}{
+			"detect_label":  "high",
+			"severity":      "Critical",
+			"disabled":      true,
+			"notifications": []interface{}{"Email,test@yelp.com"},
+		},
+	})
+
+	payload, err := getPayloadDetector(d, &signalformConfig{})
+	assert.Nil(t, err)
+	decoded := map[string]interface{}{}
+	json.Unmarshal(payload, &decoded)
+	rules := decoded["rules"].([]interface{})
+	assert.Equal(t, 1, len(rules))
+	assert.Equal(t, true, rules[0].(map[string]interface{})["disabled"])
+}
+
+func TestGetPayloadUpdateMutingRule(t *testing.T) {
+	payload, err := getPayloadUpdateMutingRule("detectorId", "detector", 300, time.Unix(1500000000, 0))
+	assert.Nil(t, err)
+	decoded := map[string]interface{}{}
+	json.Unmarshal(payload, &decoded)
+	assert.Equal(t, float64(1500000000000), decoded["startTime"])
+	assert.Equal(t, float64(1500000300000), decoded["stopTime"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"property": "sf_detectorId", "propertyValue": "detectorId", "NOT": false},
+	}, decoded["filters"])
+}
+
+func TestGetPublishLabels(t *testing.T) {
+	labels, ok := getPublishLabels("A = data('cpu.utilization')\ndetect(when(A > 90)).publish('high')\ndetect(when(A > 95)).publish(label=\"very high\", enable=False)")
+	assert.True(t, ok)
+	assert.Equal(t, map[string]bool{"high": true, "very high": true}, labels)
+
+	_, ok = getPublishLabels("for label in ['a', 'b']:\n    detect(when(data('cpu.utilization') > 90)).publish(label)")
+	assert.False(t, ok)
+}
+
+func TestGetNotificationCredentials(t *testing.T) {
+	notifications := []interface{}{
+		"PagerDuty,credId",
+		"Email,test@yelp.com",
+		"Slack,credId,alerts",
+		"PagerDuty,credId",
+		"PagerDuty,74D93920-ED26-11E3-AC10-0800200C9A66",
+	}
+	assert.Equal(t, []string{"PagerDuty,credId", "Slack,credId,alerts"}, getNotificationCredentials(notifications))
+}
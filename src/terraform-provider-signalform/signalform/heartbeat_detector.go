@@ -0,0 +1,312 @@
+package signalform
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+/*
+  Detect label of the single rule of heartbeat detectors
+*/
+const HEARTBEAT_DETECT_LABEL = "heartbeat"
+
+var heartbeatDurationRegexp = regexp.MustCompile("^[1-9][0-9]*[smhdw]$")
+
+/*
+  Heartbeat detectors alert when the reporting of a metric stops, generating the not_reporting.detector()
+  program and its rule from the metric to watch.
+*/
+func heartbeatDetectorResource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"synced": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Whether the resource in SignalForm and SignalFx are identical or not. Used internally for syncing.",
+			},
+			"last_updated": &schema.Schema{
+				Type:        schema.TypeFloat,
+				Computed:    true,
+				Description: "Latest timestamp the resource was updated",
+			},
+			"protect_from_deletion": protectFromDeletionSchema(),
+			"url": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Url of the detector",
+			},
+			"resource_url": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     DETECTOR_URL,
+				Description: "Base Detector url",
+			},
+			"program_text": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Signalflow program text generated for the detector",
+			},
+			"name": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Name of the detector",
+			},
+			"description": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Description of the detector",
+			},
+			"metric": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Metric whose reporting is watched",
+			},
+			"filter": &schema.Schema{
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "Filters restricting the watched time series",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"property": &schema.Schema{
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "A metric time series dimension or property name",
+						},
+						"negated": &schema.Schema{
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "(false by default) Whether this filter should be a \"not\" filter",
+						},
+						"values": &schema.Schema{
+							Type:        schema.TypeSet,
+							Required:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "List of strings (which will be treated as an OR filter on the property)",
+						},
+					},
+				},
+			},
+			"group_by": &schema.Schema{
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Dimensions identifying what reports the metric (e.g. host). An alert is fired for each group which stops reporting",
+			},
+			"duration": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "15m",
+				ValidateFunc: validateHeartbeatDuration,
+				Description:  "(15m by default) How long the metric must not be reported for the alert to fire (e.g. 5m, 1h)",
+			},
+			"severity": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validateSeverity,
+				Description:  "The severity of the alerts. Must be one of Critical, Major, Minor, Warning or Info",
+			},
+			"disabled": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "(default: false) When true, notifications and events will not be generated",
+			},
+			"notifications": &schema.Schema{
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString, ValidateFunc: validateNotification},
+				Description: "List of strings specifying where notifications will be sent when an incident occurs. See https://developers.signalfx.com/v2/reference#section-notifications for more info",
+			},
+			"runbook_url": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateRunbookUrl,
+				Description:  "URL of page to consult when an alert is triggered",
+			},
+			"tip": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Plain text suggested first course of action, such as a command to execute.",
+			},
+			"tags": &schema.Schema{
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Tags associated with the detector",
+			},
+			"teams": &schema.Schema{
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Team IDs to associate the detector to",
+			},
+		},
+
+		CustomizeDiff: planHeartbeatProgramText,
+
+		Create: heartbeatdetectorCreate,
+		Read:   heartbeatdetectorRead,
+		Update: heartbeatdetectorUpdate,
+		Delete: heartbeatdetectorDelete,
+	}
+}
+
+/*
+  Quotes a string for SignalFlow
+*/
+func signalflowString(value string) string {
+	return "'" + strings.Replace(strings.Replace(value, "\\", "\\\\", -1), "'", "\\'", -1) + "'"
+}
+
+/*
+  Constructs the not_reporting program of the detector, get being the getter of either the resource or
+  its diff
+*/
+func getHeartbeatProgramText(get func(string) interface{}) string {
+	filters := make([]string, 0)
+	for _, tf_filter := range get("filter").(*schema.Set).List() {
+		tf_filter := tf_filter.(map[string]interface{})
+		values := make([]string, 0)
+		for _, value := range tf_filter["values"].(*schema.Set).List() {
+			values = append(values, signalflowString(value.(string)))
+		}
+		filter := fmt.Sprintf("filter(%s, %s)", signalflowString(tf_filter["property"].(string)), strings.Join(values, ", "))
+		if tf_filter["negated"].(bool) {
+			filter = "not " + filter
+		}
+		filters = append(filters, filter)
+	}
+	// Sets are not ordered, the filters are sorted to generate a stable program
+	sort.Strings(filters)
+
+	stream := fmt.Sprintf("data(%s)", signalflowString(get("metric").(string)))
+	if len(filters) > 0 {
+		stream = fmt.Sprintf("data(%s, filter=%s)", signalflowString(get("metric").(string)), strings.Join(filters, " and "))
+	}
+	groupBy := make([]string, 0)
+	for _, dimension := range get("group_by").([]interface{}) {
+		groupBy = append(groupBy, signalflowString(dimension.(string)))
+	}
+	if len(groupBy) > 0 {
+		stream = fmt.Sprintf("%s.count(by=[%s])", stream, strings.Join(groupBy, ", "))
+	} else {
+		stream = fmt.Sprintf("%s.count()", stream)
+	}
+
+	return fmt.Sprintf("from signalfx.detectors.not_reporting import not_reporting\nnot_reporting.detector(stream=%s, duration=%s).publish(%s)",
+		stream, signalflowString(get("duration").(string)), signalflowString(HEARTBEAT_DETECT_LABEL))
+}
+
+/*
+  Shows the generated program in the plan, unless it depends on values only known at apply time
+*/
+func planHeartbeatProgramText(diff *schema.ResourceDiff, meta interface{}) error {
+	for _, key := range []string{"metric", "filter", "group_by", "duration"} {
+		if !diff.NewValueKnown(key) {
+			return diff.SetNewComputed("program_text")
+		}
+	}
+	programText := getHeartbeatProgramText(diff.Get)
+	if programText == diff.Get("program_text").(string) {
+		return nil
+	}
+	return diff.SetNew("program_text", programText)
+}
+
+/*
+  Use Resource object to construct json payload in order to create a detector
+*/
+func getPayloadHeartbeatDetector(d *schema.ResourceData) ([]byte, error) {
+	notifications, _ := d.Get("notifications").([]interface{})
+	rule := map[string]interface{}{
+		"detectLabel":   HEARTBEAT_DETECT_LABEL,
+		"severity":      d.Get("severity").(string),
+		"description":   fmt.Sprintf("%s stopped reporting for %s", d.Get("metric"), d.Get("duration")),
+		"disabled":      d.Get("disabled").(bool),
+		"notifications": getNotifications(notifications),
+	}
+	if val, ok := d.GetOk("runbook_url"); ok {
+		rule["runbookUrl"] = val.(string)
+	}
+	if val, ok := d.GetOk("tip"); ok {
+		rule["tip"] = val.(string)
+	}
+
+	payload := map[string]interface{}{
+		"name":        d.Get("name").(string),
+		"description": d.Get("description").(string),
+		"programText": getHeartbeatProgramText(d.Get),
+		"rules":       []map[string]interface{}{rule},
+		"teams":       make([]interface{}, 0),
+	}
+	if val, ok := d.GetOk("teams"); ok {
+		payload["teams"] = val.([]interface{})
+	}
+	if val, ok := d.GetOk("tags"); ok {
+		payload["tags"] = val.([]interface{})
+	}
+
+	return json.Marshal(payload)
+}
+
+func heartbeatdetectorCreate(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*signalformConfig)
+	payload, err := getPayloadHeartbeatDetector(d)
+	if err != nil {
+		return fmt.Errorf("Failed creating json payload: %s", err.Error())
+	}
+
+	if err := resourceCreate(DETECTOR_API_URL, config, payload, d); err != nil {
+		return err
+	}
+	return heartbeatdetectorRead(d, meta)
+}
+
+func heartbeatdetectorRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*signalformConfig)
+	url := fmt.Sprintf("%s/%s", DETECTOR_API_URL, d.Id())
+
+	return resourceReadWithState(url, config, d, func(detector map[string]interface{}, d *schema.ResourceData) error {
+		programText, _ := detector["programText"].(string)
+		return d.Set("program_text", programText)
+	})
+}
+
+func heartbeatdetectorUpdate(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*signalformConfig)
+	payload, err := getPayloadHeartbeatDetector(d)
+	if err != nil {
+		return fmt.Errorf("Failed creating json payload: %s", err.Error())
+	}
+	url := fmt.Sprintf("%s/%s", DETECTOR_API_URL, d.Id())
+
+	if err := resourceUpdate(url, config, payload, d); err != nil {
+		return err
+	}
+	return heartbeatdetectorRead(d, meta)
+}
+
+func heartbeatdetectorDelete(d *schema.ResourceData, meta interface{}) error {
+	if err := checkDeletionProtection(d); err != nil {
+		return err
+	}
+	config := meta.(*signalformConfig)
+	url := fmt.Sprintf("%s/%s", DETECTOR_API_URL, d.Id())
+
+	return resourceDelete(url, config, d)
+}
+
+func validateHeartbeatDuration(v interface{}, k string) (we []string, errors []error) {
+	value := v.(string)
+	if !heartbeatDurationRegexp.MatchString(value) {
+		errors = append(errors, fmt.Errorf("%s not allowed; %s must be a SignalFlow duration (e.g. 5m, 1h)", value, k))
+	}
+	return
+}
@@ -0,0 +1,53 @@
+package signalform
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGetHeartbeatProgramText(t *testing.T) {
+	d := heartbeatDetectorResource().TestResourceData()
+	d.Set("metric", "app.requests")
+	d.Set("duration", "5m")
+	assert.Equal(t, "from signalfx.detectors.not_reporting import not_reporting\nnot_reporting.detector(stream=data('app.requests').count(), duration='5m').publish('heartbeat')", getHeartbeatProgramText(d.Get))
+
+	d.Set("group_by", []interface{}{"host"})
+	d.Set("filter", []interface{}{
+		map[string]interface{}{"property": "env", "negated": true, "values": []interface{}{"dev"}},
+		map[string]interface{}{"property": "cluster", "negated": false, "values": []interface{}{"it's"}},
+	})
+	assert.Equal(t, "from signalfx.detectors.not_reporting import not_reporting\nnot_reporting.detector(stream=data('app.requests', filter=filter('cluster', 'it\\'s') and not filter('env', 'dev')).count(by=['host']), duration='5m').publish('heartbeat')", getHeartbeatProgramText(d.Get))
+}
+
+func TestGetPayloadHeartbeatDetector(t *testing.T) {
+	d := heartbeatDetectorResource().TestResourceData()
+	d.Set("name", "heartbeat")
+	d.Set("metric", "app.requests")
+	d.Set("duration", "15m")
+	d.Set("severity", "Critical")
+	d.Set("notifications", []interface{}{"Email,test@yelp.com"})
+
+	payload, err := getPayloadHeartbeatDetector(d)
+	assert.Nil(t, err)
+	decoded := map[string]interface{}{}
+	json.Unmarshal(payload, &decoded)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{
+			"detectLabel":   "heartbeat",
+			"severity":      "Critical",
+			"description":   "app.requests stopped reporting for 15m",
+			"disabled":      false,
+			"notifications": []interface{}{map[string]interface{}{"type": "Email", "email": "test@yelp.com"}},
+		},
+	}, decoded["rules"])
+}
+
+func TestValidateHeartbeatDuration(t *testing.T) {
+	_, errors := validateHeartbeatDuration("5m", "duration")
+	assert.Equal(t, 0, len(errors))
+	for _, value := range []string{"-5m", "5", "0m", "5 minutes"} {
+		_, errors := validateHeartbeatDuration(value, "duration")
+		assert.Equal(t, 1, len(errors), value)
+	}
+}
@@ -3,11 +3,16 @@ package signalform
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/hashicorp/terraform/helper/customdiff"
 	"github.com/hashicorp/terraform/helper/schema"
 	"math"
+	"regexp"
 	"strings"
 )
 
+// Unlike the other colors, the color of heatmap color ranges is sent as hex, so custom colors can be used
+var hexColorRegexp = regexp.MustCompile("^#[0-9a-fA-F]{6}$")
+
 func heatmapChartResource() *schema.Resource {
 	return &schema.Resource{
 		Schema: map[string]*schema.Schema{
@@ -49,9 +54,10 @@ func heatmapChartResource() *schema.Resource {
 				Description: "Signalflow program text for the chart. More info at \"https://developers.signalfx.com/docs/signalflow-overview\"",
 			},
 			"unit_prefix": &schema.Schema{
-				Type:        schema.TypeString,
-				Optional:    true,
-				Description: "(Metric by default) Must be \"Metric\" or \"Binary\"",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "(Metric by default) Must be \"Metric\" or \"Binary\"",
+				ValidateFunc: validateUnitPrefix,
 			},
 			"minimum_resolution": &schema.Schema{
 				Type:        schema.TypeInt,
@@ -64,11 +70,16 @@ func heatmapChartResource() *schema.Resource {
 				Description:  "How long (in seconds) to wait for late datapoints",
 				ValidateFunc: validateMaxDelayValue,
 			},
+			"timezone": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateTimezone,
+				Description:  "Timezone in which the chart is rendered (e.g. UTC, Europe/Paris), whatever the timezone of the viewer",
+			},
 			"disable_sampling": &schema.Schema{
 				Type:        schema.TypeBool,
 				Optional:    true,
-				Default:     false,
-				Description: "(false by default) If false, samples a subset of the output MTS, which improves UI performance",
+				Description: "(default_disable_sampling of the provider by default) If false, samples a subset of the output MTS, which improves UI performance",
 			},
 			"group_by": &schema.Schema{
 				Type:        schema.TypeList,
@@ -83,9 +94,11 @@ func heatmapChartResource() *schema.Resource {
 				Description:  "The property to use when sorting the elements. Must be prepended with + for ascending or - for descending (e.g. -foo)",
 			},
 			"color_range": &schema.Schema{
-				Type:        schema.TypeSet,
-				Optional:    true,
-				Description: "Values and color for the color range. Example: colorRange : { min : 0, max : 100, color : \"blue\" }",
+				Type:          schema.TypeSet,
+				Optional:      true,
+				MaxItems:      1,
+				ConflictsWith: []string{"color_scale"},
+				Description:   "Values and color for the color range. Example: colorRange : { min : 0, max : 100, color : \"blue\" }",
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"min_value": &schema.Schema{
@@ -103,51 +116,13 @@ func heatmapChartResource() *schema.Resource {
 						"color": &schema.Schema{
 							Type:         schema.TypeString,
 							Required:     true,
-							Description:  "The color range to use. Must be either \"gray\", \"blue\", \"navy\", \"orange\", \"yellow\", \"magenta\", \"purple\", \"violet\", \"lilac\", \"green\", \"aquamarine\"",
-							ValidateFunc: validateHeatmapChartColor,
-						},
-					},
-				},
-			},
-			"color_scale": &schema.Schema{
-				Type:        schema.TypeSet,
-				Optional:    true,
-				Description: "Single color range including both the color to display for that range and the borders of the range",
-				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						"gt": &schema.Schema{
-							Type:        schema.TypeFloat,
-							Optional:    true,
-							Default:     math.MaxFloat32,
-							Description: "Indicates the lower threshold non-inclusive value for this range",
-						},
-						"gte": &schema.Schema{
-							Type:        schema.TypeFloat,
-							Optional:    true,
-							Default:     math.MaxFloat32,
-							Description: "Indicates the lower threshold inclusive value for this range",
-						},
-						"lt": &schema.Schema{
-							Type:        schema.TypeFloat,
-							Optional:    true,
-							Default:     math.MaxFloat32,
-							Description: "Indicates the upper threshold non-inculsive value for this range",
-						},
-						"lte": &schema.Schema{
-							Type:        schema.TypeFloat,
-							Optional:    true,
-							Default:     math.MaxFloat32,
-							Description: "Indicates the upper threshold inclusive value for this range",
-						},
-						"color": &schema.Schema{
-							Type:         schema.TypeString,
-							Required:     true,
-							Description:  "The color to use. Must be either \"gray\", \"blue\", \"navy\", \"orange\", \"yellow\", \"magenta\", \"purple\", \"violet\", \"lilac\", \"green\", \"aquamarine\"",
-							ValidateFunc: validateHeatmapChartColor,
+							Description:  "The color range to use. Must be either \"gray\", \"blue\", \"navy\", \"orange\", \"yellow\", \"magenta\", \"purple\", \"violet\", \"lilac\", \"green\", \"aquamarine\", or a hex color (e.g. \"#ff8800\")",
+							ValidateFunc: validateHeatmapColorRangeColor,
 						},
 					},
 				},
 			},
+			"color_scale": colorScaleSchema(),
 			"hide_timestamp": &schema.Schema{
 				Type:        schema.TypeBool,
 				Optional:    true,
@@ -160,20 +135,22 @@ func heatmapChartResource() *schema.Resource {
 		Read:   heatmapchartRead,
 		Update: heatmapchartUpdate,
 		Delete: heatmapchartDelete,
+
+		CustomizeDiff: customdiff.All(validateProgramTextPublishes, validateColorScale, validateHeatmapColorRange, validateGroupBy),
 	}
 }
 
 /*
   Use Resource object to construct json payload in order to create an Heatmap chart
 */
-func getPayloadHeatmapChart(d *schema.ResourceData) ([]byte, error) {
+func getPayloadHeatmapChart(d *schema.ResourceData, config *signalformConfig) ([]byte, error) {
 	payload := map[string]interface{}{
 		"name":        d.Get("name").(string),
 		"description": d.Get("description").(string),
 		"programText": d.Get("program_text").(string),
 	}
 
-	viz := getHeatmapOptionsChart(d)
+	viz := getHeatmapOptionsChart(d, config)
 	if len(viz) > 0 {
 		payload["options"] = viz
 	}
@@ -181,6 +158,30 @@ func getPayloadHeatmapChart(d *schema.ResourceData) ([]byte, error) {
 	return json.Marshal(payload)
 }
 
+/*
+  The color range is a gradient between min_value and max_value, which are easily swapped
+*/
+func validateHeatmapColorRange(diff *schema.ResourceDiff, meta interface{}) error {
+	if !diff.NewValueKnown("color_range") {
+		return nil
+	}
+	for _, colorRange := range diff.Get("color_range").(*schema.Set).List() {
+		if err := checkHeatmapColorRange(colorRange.(map[string]interface{})); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkHeatmapColorRange(colorRange map[string]interface{}) error {
+	minValue, _ := colorRange["min_value"].(float64)
+	maxValue, _ := colorRange["max_value"].(float64)
+	if minValue >= maxValue {
+		return fmt.Errorf("color_range: min_value (%v) must be lower than max_value (%v)", minValue, maxValue)
+	}
+	return nil
+}
+
 func getHeatmapColorRangeOptions(d *schema.ResourceData) map[string]interface{} {
 	item := make(map[string]interface{})
 	colorRange := d.Get("color_range").(*schema.Set).List()
@@ -198,12 +199,15 @@ func getHeatmapColorRangeOptions(d *schema.ResourceData) map[string]interface{}
 			}
 		}
 		color := options["color"].(string)
-		item["color"] = ChartColors[color]
+		if hexColor, ok := ChartColors[color]; ok {
+			color = hexColor
+		}
+		item["color"] = color
 	}
 	return item
 }
 
-func getHeatmapOptionsChart(d *schema.ResourceData) map[string]interface{} {
+func getHeatmapOptionsChart(d *schema.ResourceData, config *signalformConfig) map[string]interface{} {
 	viz := make(map[string]interface{})
 	viz["type"] = "Heatmap"
 	if val, ok := d.GetOk("unit_prefix"); ok {
@@ -214,11 +218,14 @@ func getHeatmapOptionsChart(d *schema.ResourceData) map[string]interface{} {
 	if val, ok := d.GetOk("minimum_resolution"); ok {
 		programOptions["minimumResolution"] = val.(int) * 1000
 	}
-	if val, ok := d.GetOk("max_delay"); ok {
-		programOptions["maxDelay"] = val.(int) * 1000
+	if maxDelay, ok := getMaxDelay(d.GetOkExists, config); ok {
+		programOptions["maxDelay"] = maxDelay * 1000
 	}
-	programOptions["disableSampling"] = d.Get("disable_sampling").(bool)
+	programOptions["disableSampling"] = getDisableSampling(d, config)
 	viz["programOptions"] = programOptions
+	if val, ok := d.GetOk("timezone"); ok {
+		viz["timezone"] = val.(string)
+	}
 
 	if groupByOptions, ok := d.GetOk("group_by"); ok {
 		viz["groupBy"] = groupByOptions.([]interface{})
@@ -249,40 +256,40 @@ func getHeatmapOptionsChart(d *schema.ResourceData) map[string]interface{} {
 
 func heatmapchartCreate(d *schema.ResourceData, meta interface{}) error {
 	config := meta.(*signalformConfig)
-	payload, err := getPayloadHeatmapChart(d)
+	payload, err := getPayloadHeatmapChart(d, config)
 	if err != nil {
 		return fmt.Errorf("Failed creating json payload: %s", err.Error())
 	}
 
-	return resourceCreate(CHART_API_URL, config.AuthToken, payload, d)
+	return resourceCreate(CHART_API_URL, config, payload, d)
 }
 
 func heatmapchartRead(d *schema.ResourceData, meta interface{}) error {
 	config := meta.(*signalformConfig)
 	url := fmt.Sprintf("%s/%s", CHART_API_URL, d.Id())
 
-	return resourceRead(url, config.AuthToken, d)
+	return resourceRead(url, config, d)
 }
 
 func heatmapchartUpdate(d *schema.ResourceData, meta interface{}) error {
 	config := meta.(*signalformConfig)
-	payload, err := getPayloadHeatmapChart(d)
+	payload, err := getPayloadHeatmapChart(d, config)
 	if err != nil {
 		return fmt.Errorf("Failed creating json payload: %s", err.Error())
 	}
 	url := fmt.Sprintf("%s/%s", CHART_API_URL, d.Id())
 
-	return resourceUpdate(url, config.AuthToken, payload, d)
+	return resourceUpdate(url, config, payload, d)
 }
 
 func heatmapchartDelete(d *schema.ResourceData, meta interface{}) error {
 	config := meta.(*signalformConfig)
 	url := fmt.Sprintf("%s/%s", CHART_API_URL, d.Id())
-	return resourceDelete(url, config.AuthToken, d)
+	return resourceDelete(url, config, d)
 }
 
 /*
-  Validates the color_range field against a list of allowed words.
+  Validates the color of color scales against a list of allowed words.
 */
 func validateHeatmapChartColor(v interface{}, k string) (we []string, errors []error) {
 	value := v.(string)
@@ -296,3 +303,17 @@ func validateHeatmapChartColor(v interface{}, k string) (we []string, errors []e
 	}
 	return
 }
+
+/*
+  Same as validateHeatmapChartColor, but also accepts #RRGGBB hex colors
+*/
+func validateHeatmapColorRangeColor(v interface{}, k string) (we []string, errors []error) {
+	value := v.(string)
+	if hexColorRegexp.MatchString(value) {
+		return
+	}
+	if _, errors = validateHeatmapChartColor(value, k); len(errors) > 0 {
+		errors[0] = fmt.Errorf("%s, or a hex color (e.g. #ff8800)", errors[0].Error())
+	}
+	return
+}
@@ -2,6 +2,7 @@ package signalform
 
 import (
 	"github.com/stretchr/testify/assert"
+	"math"
 	"testing"
 )
 
@@ -13,4 +14,50 @@ func TestValidateHeatmapChartColors(t *testing.T) {
 func TestValidateHeatmapChartColorsFail(t *testing.T) {
 	_, err := validateHeatmapChartColor("whatever", "color")
 	assert.Equal(t, 1, len(err))
+	_, err = validateHeatmapChartColor("#ff8800", "color")
+	assert.Equal(t, 1, len(err))
+}
+
+func TestValidateHeatmapColorRangeColor(t *testing.T) {
+	for _, color := range []string{"blue", "#ff8800", "#FF8800"} {
+		_, err := validateHeatmapColorRangeColor(color, "color")
+		assert.Equal(t, 0, len(err), color)
+	}
+	for _, color := range []string{"whatever", "#ff88", "ff8800", "#gg8800"} {
+		_, err := validateHeatmapColorRangeColor(color, "color")
+		assert.Equal(t, 1, len(err), color)
+	}
+	_, err := validateHeatmapColorRangeColor("whatever", "color")
+	assert.Contains(t, err[0].Error(), "or a hex color")
+}
+
+func TestCheckHeatmapColorRange(t *testing.T) {
+	assert.Nil(t, checkHeatmapColorRange(map[string]interface{}{"min_value": 0.0, "max_value": 100.0, "color": "blue"}))
+	assert.Nil(t, checkHeatmapColorRange(map[string]interface{}{"min_value": -math.MaxFloat32, "max_value": math.MaxFloat32, "color": "blue"}))
+
+	err := checkHeatmapColorRange(map[string]interface{}{"min_value": 100.0, "max_value": 0.0, "color": "blue"})
+	assert.Contains(t, err.Error(), "must be lower than max_value")
+}
+
+func TestGetHeatmapOptionsChartColorRange(t *testing.T) {
+	d := heatmapChartResource().TestResourceData()
+	d.Set("color_range", []interface{}{
+		map[string]interface{}{"min_value": 0.0, "max_value": 100.0, "color": "blue"},
+	})
+	viz := getHeatmapOptionsChart(d, &signalformConfig{})
+	assert.Equal(t, "Range", viz["colorBy"])
+	assert.Equal(t, map[string]interface{}{"min": 0.0, "max": 100.0, "color": "#0077c2"}, viz["colorRange"])
+
+	d.Set("color_range", []interface{}{
+		map[string]interface{}{"min_value": 0.0, "max_value": 100.0, "color": "#ff8800"},
+	})
+	viz = getHeatmapOptionsChart(d, &signalformConfig{})
+	assert.Equal(t, map[string]interface{}{"min": 0.0, "max": 100.0, "color": "#ff8800"}, viz["colorRange"])
+}
+
+func TestGetHeatmapOptionsChartGroupBy(t *testing.T) {
+	d := heatmapChartResource().TestResourceData()
+	d.Set("group_by", []interface{}{"aws_availability_zone", "host"})
+	viz := getHeatmapOptionsChart(d, &signalformConfig{})
+	assert.Equal(t, []interface{}{"aws_availability_zone", "host"}, viz["groupBy"])
 }
@@ -1,6 +1,7 @@
 package signalform
 
 import (
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -26,6 +27,7 @@ func integrationResource() *schema.Resource {
 				Computed:    true,
 				Description: "Latest timestamp the resource was updated",
 			},
+			"protect_from_deletion": protectFromDeletionSchema(),
 			"name": &schema.Schema{
 				Type:        schema.TypeString,
 				Required:    true,
@@ -39,9 +41,21 @@ func integrationResource() *schema.Resource {
 			"type": &schema.Schema{
 				Type:         schema.TypeString,
 				Required:     true,
-				Description:  "Type of the integration",
+				ForceNew:     true,
+				Description:  "Type of the integration. Changing it re-creates the integration",
 				ValidateFunc: validateIntegrationType,
 			},
+			"validate": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "(false by default) Whether SignalFx should verify the credentials of the integration when it is created or updated",
+			},
+			"credential_hash": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "SHA-256 hash of the credential last sent to SignalFx. Used internally to detect credential drift",
+			},
 			"api_key": &schema.Schema{
 				Type:          schema.TypeString,
 				Optional:      true,
@@ -62,6 +76,8 @@ func integrationResource() *schema.Resource {
 		Read:   integrationRead,
 		Update: integrationUpdate,
 		Delete: integrationDelete,
+
+		DeprecationMessage: "signalform_integration is deprecated, use the per-type resources (e.g. signalform_pagerduty_integration, signalform_slack_integration) instead",
 	}
 }
 
@@ -95,22 +111,104 @@ func getPayloadIntegration(d *schema.ResourceData) ([]byte, error) {
 	return json.Marshal(payload)
 }
 
+/*
+  Returns the schema attribute and the API field holding the credential of the given integration type
+*/
+func getIntegrationCredentialFields(integrationType string) (string, string) {
+	switch integrationType {
+	case "PagerDuty":
+		return "api_key", "apiKey"
+	case "Slack":
+		return "webhook_url", "webhookUrl"
+	}
+	return "", ""
+}
+
+func getCredentialHash(credential string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(credential)))
+}
+
+/*
+  SignalFx masks secrets when returning an integration (e.g. "****************abcd"), so a masked
+  or missing value tells nothing about drift and must be ignored.
+*/
+func isMaskedCredential(credential string) bool {
+	return credential == "" || strings.Contains(credential, "*")
+}
+
+/*
+  Records the hash of the credential about to be sent, so that drift can be detected on read
+*/
+func setCredentialHash(d *schema.ResourceData, attribute string) error {
+	return d.Set("credential_hash", getCredentialHash(d.Get(attribute).(string)))
+}
+
+/*
+  Only an unmasked credential which differs from the last one sent by Signalform is reflected in the
+  state. This way rotating a key produces exactly one diff, and masked read-backs never produce any.
+*/
+func credentialAPIToState(integration map[string]interface{}, d *schema.ResourceData, attribute string, field string) error {
+	credential, ok := integration[field].(string)
+	if !ok || isMaskedCredential(credential) {
+		return nil
+	}
+	if getCredentialHash(credential) == d.Get("credential_hash").(string) {
+		return nil
+	}
+	return d.Set(attribute, credential)
+}
+
+/*
+  Appends the skipValidation query parameter, so that credentials are only verified by SignalFx when asked to
+*/
+func getIntegrationUrl(url string, d *schema.ResourceData) string {
+	return fmt.Sprintf("%s?skipValidation=%t", url, !d.Get("validate").(bool))
+}
+
+/*
+  Sets the attributes common to every integration type from the API response
+*/
+func integrationAPIToState(integration map[string]interface{}, d *schema.ResourceData) error {
+	if val, ok := integration["name"].(string); ok {
+		if err := d.Set("name", val); err != nil {
+			return err
+		}
+	}
+	if val, ok := integration["enabled"].(bool); ok {
+		if err := d.Set("enabled", val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func integrationCreate(d *schema.ResourceData, meta interface{}) error {
 	config := meta.(*signalformConfig)
 	payload, err := getPayloadIntegration(d)
 	if err != nil {
 		return fmt.Errorf("Failed creating json payload: %s", err.Error())
 	}
-	url := fmt.Sprintf("%s?skipValidation=true", INTEGRATION_API_URL)
+	url := getIntegrationUrl(INTEGRATION_API_URL, d)
+	if attribute, _ := getIntegrationCredentialFields(d.Get("type").(string)); attribute != "" {
+		setCredentialHash(d, attribute)
+	}
 
-	return resourceCreate(url, config.AuthToken, payload, d)
+	return resourceCreate(url, config, payload, d)
 }
 
 func integrationRead(d *schema.ResourceData, meta interface{}) error {
 	config := meta.(*signalformConfig)
 	url := fmt.Sprintf("%s/%s", INTEGRATION_API_URL, d.Id())
 
-	return resourceRead(url, config.AuthToken, d)
+	return resourceReadWithState(url, config, d, integrationCredentialAPIToState)
+}
+
+func integrationCredentialAPIToState(integration map[string]interface{}, d *schema.ResourceData) error {
+	attribute, field := getIntegrationCredentialFields(d.Get("type").(string))
+	if attribute == "" {
+		return nil
+	}
+	return credentialAPIToState(integration, d, attribute, field)
 }
 
 func integrationUpdate(d *schema.ResourceData, meta interface{}) error {
@@ -119,13 +217,145 @@ func integrationUpdate(d *schema.ResourceData, meta interface{}) error {
 	if err != nil {
 		return fmt.Errorf("Failed creating json payload: %s", err.Error())
 	}
-	url := fmt.Sprintf("%s/%s", INTEGRATION_API_URL, d.Id())
+	url := getIntegrationUrl(fmt.Sprintf("%s/%s", INTEGRATION_API_URL, d.Id()), d)
+	if attribute, _ := getIntegrationCredentialFields(d.Get("type").(string)); attribute != "" {
+		setCredentialHash(d, attribute)
+	}
+
+	return resourceUpdate(url, config, payload, d)
+}
+
+/*
+  Builds the resource managing integrations of a single type, e.g. signalform_pagerduty_integration.
+  The credential attribute is the one getIntegrationCredentialFields returns for the type.
+*/
+func typedIntegrationResource(integrationType string, credentialDescription string) *schema.Resource {
+	attribute, field := getIntegrationCredentialFields(integrationType)
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"synced": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Whether the resource in SignalForm and SignalFx are identical or not. Used internally for syncing.",
+			},
+			"last_updated": &schema.Schema{
+				Type:        schema.TypeFloat,
+				Computed:    true,
+				Description: "Latest timestamp the resource was updated",
+			},
+			"protect_from_deletion": protectFromDeletionSchema(),
+			"name": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Name of the integration",
+			},
+			"enabled": &schema.Schema{
+				Type:        schema.TypeBool,
+				Required:    true,
+				Description: "Whether the integration is enabled or not",
+			},
+			"validate": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "(false by default) Whether SignalFx should verify the credentials of the integration when it is created or updated",
+			},
+			"credential_hash": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "SHA-256 hash of the credential last sent to SignalFx. Used internally to detect credential drift",
+			},
+			attribute: &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Sensitive:   true,
+				Description: credentialDescription,
+			},
+		},
+
+		Create: func(d *schema.ResourceData, meta interface{}) error {
+			config := meta.(*signalformConfig)
+			payload, err := getPayloadTypedIntegration(integrationType, d)
+			if err != nil {
+				return fmt.Errorf("Failed creating json payload: %s", err.Error())
+			}
+			url := getIntegrationUrl(INTEGRATION_API_URL, d)
+			setCredentialHash(d, attribute)
+
+			return resourceCreate(url, config, payload, d)
+		},
+		Read: func(d *schema.ResourceData, meta interface{}) error {
+			config := meta.(*signalformConfig)
+			url := fmt.Sprintf("%s/%s", INTEGRATION_API_URL, d.Id())
+
+			return resourceReadWithState(url, config, d, func(integration map[string]interface{}, d *schema.ResourceData) error {
+				if err := integrationAPIToState(integration, d); err != nil {
+					return err
+				}
+				return credentialAPIToState(integration, d, attribute, field)
+			})
+		},
+		Update: func(d *schema.ResourceData, meta interface{}) error {
+			config := meta.(*signalformConfig)
+			payload, err := getPayloadTypedIntegration(integrationType, d)
+			if err != nil {
+				return fmt.Errorf("Failed creating json payload: %s", err.Error())
+			}
+			url := getIntegrationUrl(fmt.Sprintf("%s/%s", INTEGRATION_API_URL, d.Id()), d)
+			setCredentialHash(d, attribute)
 
-	return resourceUpdate(url, config.AuthToken, payload, d)
+			return resourceUpdate(url, config, payload, d)
+		},
+		Delete: integrationDelete,
+		Importer: &schema.ResourceImporter{
+			State: typedIntegrationImport(integrationType),
+		},
+	}
+}
+
+func getPayloadTypedIntegration(integrationType string, d *schema.ResourceData) ([]byte, error) {
+	attribute, field := getIntegrationCredentialFields(integrationType)
+	payload := map[string]interface{}{
+		"name":    d.Get("name").(string),
+		"enabled": d.Get("enabled").(bool),
+		"type":    integrationType,
+		field:     d.Get(attribute).(string),
+	}
+
+	return json.Marshal(payload)
+}
+
+/*
+  Importing an integration of another type would only fail on the next apply, when SignalFx rejects
+  the payload, so the type is checked right away
+*/
+func typedIntegrationImport(integrationType string) schema.StateFunc {
+	return func(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+		config := meta.(*signalformConfig)
+		integration, err := getSignalFxObject(fmt.Sprintf("%s/%s", INTEGRATION_API_URL, d.Id()), config)
+		if err != nil {
+			return nil, fmt.Errorf("Reading integration %s: %s", d.Id(), err.Error())
+		}
+		if err := checkIntegrationType(integration, integrationType); err != nil {
+			return nil, err
+		}
+		return []*schema.ResourceData{d}, nil
+	}
+}
+
+func checkIntegrationType(integration map[string]interface{}, integrationType string) error {
+	if actual, _ := integration["type"].(string); actual != integrationType {
+		return fmt.Errorf("Integration %v is a %s integration, not a %s one", integration["id"], actual, integrationType)
+	}
+	return nil
 }
 
 func integrationDelete(d *schema.ResourceData, meta interface{}) error {
+	if err := checkDeletionProtection(d); err != nil {
+		return err
+	}
 	config := meta.(*signalformConfig)
 	url := fmt.Sprintf("%s/%s", INTEGRATION_API_URL, d.Id())
-	return resourceDelete(url, config.AuthToken, d)
+	return resourceDelete(url, config, d)
 }
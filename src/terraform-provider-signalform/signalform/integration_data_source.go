@@ -0,0 +1,66 @@
+package signalform
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+/*
+  Data source looking up an integration by type and name, so that notification strings can use its
+  credential ID rather than an ID hard-coded for each organization
+*/
+func integrationDataSource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"type": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Type of the integration (e.g. PagerDuty, Slack)",
+			},
+			"name": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Name of the integration. It must match exactly one integration of this type",
+			},
+			"enabled": &schema.Schema{
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the integration is enabled",
+			},
+		},
+
+		Read: integrationDataSourceRead,
+	}
+}
+
+func integrationDataSourceRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*signalformConfig)
+	integrationType := d.Get("type").(string)
+	name := d.Get("name").(string)
+
+	query := url.Values{"type": []string{integrationType}, "name": []string{name}}
+	integrations, err := searchSignalFxObjects(INTEGRATION_API_URL, query, config)
+	if err != nil {
+		return fmt.Errorf("Searching integrations: %s", err.Error())
+	}
+	integration, err := findIntegration(integrations, integrationType, name)
+	if err != nil {
+		return err
+	}
+
+	d.SetId(integration["id"].(string))
+	enabled, _ := integration["enabled"].(bool)
+	return d.Set("enabled", enabled)
+}
+
+func findIntegration(integrations []map[string]interface{}, integrationType string, name string) (map[string]interface{}, error) {
+	ofType := make([]map[string]interface{}, 0)
+	for _, integration := range integrations {
+		if integration["type"] == integrationType {
+			ofType = append(ofType, integration)
+		}
+	}
+	return findObjectByName(integrationType+" integration", ofType, name)
+}
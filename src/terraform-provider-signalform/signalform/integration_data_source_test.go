@@ -0,0 +1,24 @@
+package signalform
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFindIntegration(t *testing.T) {
+	integrations := []map[string]interface{}{
+		map[string]interface{}{"id": "I1", "type": "PagerDuty", "name": "Oncall"},
+		map[string]interface{}{"id": "I2", "type": "Slack", "name": "Oncall"},
+		map[string]interface{}{"id": "I3", "type": "PagerDuty", "name": "Oncall secondary"},
+	}
+	integration, err := findIntegration(integrations, "PagerDuty", "Oncall")
+	assert.Nil(t, err)
+	assert.Equal(t, "I1", integration["id"])
+
+	integration, err = findIntegration(integrations, "Slack", "Oncall")
+	assert.Nil(t, err)
+	assert.Equal(t, "I2", integration["id"])
+
+	_, err = findIntegration(integrations, "Slack", "Oncall secondary")
+	assert.Contains(t, err.Error(), "No Slack integration named Oncall secondary")
+}
@@ -0,0 +1,77 @@
+package signalform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetIntegrationUrl(t *testing.T) {
+	d := integrationResource().TestResourceData()
+	assert.Equal(t, INTEGRATION_API_URL+"?skipValidation=true", getIntegrationUrl(INTEGRATION_API_URL, d))
+
+	d.Set("validate", true)
+	assert.Equal(t, INTEGRATION_API_URL+"/abc?skipValidation=false", getIntegrationUrl(INTEGRATION_API_URL+"/abc", d))
+}
+
+func TestIsMaskedCredential(t *testing.T) {
+	assert.True(t, isMaskedCredential(""))
+	assert.True(t, isMaskedCredential("************abcd"))
+	assert.False(t, isMaskedCredential("1234567890"))
+}
+
+func TestCredentialAPIToState(t *testing.T) {
+	d := pagerDutyIntegrationResource().TestResourceData()
+	d.Set("api_key", "1234567890")
+	setCredentialHash(d, "api_key")
+
+	// Masked values never produce a diff
+	assert.Nil(t, credentialAPIToState(map[string]interface{}{"apiKey": "******7890"}, d, "api_key", "apiKey"))
+	assert.Equal(t, "1234567890", d.Get("api_key"))
+
+	// The same credential doesn't produce a diff either
+	assert.Nil(t, credentialAPIToState(map[string]interface{}{"apiKey": "1234567890"}, d, "api_key", "apiKey"))
+	assert.Equal(t, "1234567890", d.Get("api_key"))
+
+	// A credential rotated outside of Signalform is reflected in the state
+	assert.Nil(t, credentialAPIToState(map[string]interface{}{"apiKey": "0987654321"}, d, "api_key", "apiKey"))
+	assert.Equal(t, "0987654321", d.Get("api_key"))
+}
+
+func TestGetPayloadTypedIntegration(t *testing.T) {
+	d := slackIntegrationResource().TestResourceData()
+	d.Set("name", "Slack - My Team")
+	d.Set("enabled", true)
+	d.Set("webhook_url", "https://hooks.slack.com/services/abc")
+
+	payload, err := getPayloadTypedIntegration("Slack", d)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"name": "Slack - My Team", "enabled": true, "type": "Slack", "webhookUrl": "https://hooks.slack.com/services/abc"}`, string(payload))
+
+	d = pagerDutyIntegrationResource().TestResourceData()
+	d.Set("name", "PD - My Team")
+	d.Set("enabled", false)
+	d.Set("api_key", "1234567890")
+
+	payload, err = getPayloadTypedIntegration("PagerDuty", d)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"name": "PD - My Team", "enabled": false, "type": "PagerDuty", "apiKey": "1234567890"}`, string(payload))
+}
+
+func TestTypedIntegrationSchema(t *testing.T) {
+	pagerDuty := pagerDutyIntegrationResource().Schema
+	assert.Contains(t, pagerDuty, "api_key")
+	assert.NotContains(t, pagerDuty, "webhook_url")
+	assert.NotContains(t, pagerDuty, "type")
+
+	slack := slackIntegrationResource().Schema
+	assert.Contains(t, slack, "webhook_url")
+	assert.NotContains(t, slack, "api_key")
+}
+
+func TestCheckIntegrationType(t *testing.T) {
+	assert.Nil(t, checkIntegrationType(map[string]interface{}{"id": "abc", "type": "Slack"}, "Slack"))
+
+	err := checkIntegrationType(map[string]interface{}{"id": "abc", "type": "PagerDuty"}, "Slack")
+	assert.EqualError(t, err, "Integration abc is a PagerDuty integration, not a Slack one")
+}
@@ -0,0 +1,56 @@
+package signalform
+
+import (
+	"sync"
+	"time"
+)
+
+// Slots of the requests in flight, nil when their number is not capped. Set by the provider configuration.
+var requestSlots chan struct{}
+
+// Minimum time between the starts of two requests, 0 when the rate is not limited. Set by the provider configuration.
+var requestInterval time.Duration
+
+var requestRateLock sync.Mutex
+var nextRequestTime time.Time
+
+/*
+  Caps the requests sent to SignalFx by all the resources, so that large applies stay within the API quota of
+  the organization. 0 disables a limit.
+*/
+func setRequestLimits(maxConcurrentRequests int, requestsPerSecond float64) {
+	requestSlots = nil
+	if maxConcurrentRequests > 0 {
+		requestSlots = make(chan struct{}, maxConcurrentRequests)
+	}
+	requestInterval = 0
+	if requestsPerSecond > 0 {
+		requestInterval = time.Duration(float64(time.Second) / requestsPerSecond)
+	}
+}
+
+/*
+  Blocks until a request may be sent, and returns the function releasing its slot once it completed
+*/
+func waitForRequestSlot() func() {
+	slots := requestSlots
+	if slots != nil {
+		slots <- struct{}{}
+	}
+	if requestInterval > 0 {
+		requestRateLock.Lock()
+		now := time.Now()
+		start := nextRequestTime
+		if start.Before(now) {
+			start = now
+		}
+		nextRequestTime = start.Add(requestInterval)
+		requestRateLock.Unlock()
+		time.Sleep(start.Sub(now))
+	}
+	return func() {
+		if slots != nil {
+			<-slots
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package signalform
+
+import (
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWaitForRequestSlotRate(t *testing.T) {
+	setRequestLimits(0, 50)
+	defer setRequestLimits(0, 0)
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		waitForRequestSlot()()
+	}
+	// The first request starts right away, the 4 others 20ms apart
+	assert.True(t, time.Since(start) >= 80*time.Millisecond)
+}
+
+func TestWaitForRequestSlotConcurrency(t *testing.T) {
+	setRequestLimits(2, 0)
+	defer setRequestLimits(0, 0)
+
+	var lock sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release := waitForRequestSlot()
+			lock.Lock()
+			inFlight++
+			if inFlight > maxInFlight {
+				maxInFlight = inFlight
+			}
+			lock.Unlock()
+			time.Sleep(5 * time.Millisecond)
+			lock.Lock()
+			inFlight--
+			lock.Unlock()
+			release()
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 2, maxInFlight)
+}
+
+func TestWaitForRequestSlotUnlimited(t *testing.T) {
+	setRequestLimits(0, 0)
+	start := time.Now()
+	for i := 0; i < 100; i++ {
+		waitForRequestSlot()()
+	}
+	assert.True(t, time.Since(start) < 50*time.Millisecond)
+}
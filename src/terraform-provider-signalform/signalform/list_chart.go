@@ -3,6 +3,7 @@ package signalform
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/hashicorp/terraform/helper/customdiff"
 	"github.com/hashicorp/terraform/helper/schema"
 )
 
@@ -47,14 +48,22 @@ func listChartResource() *schema.Resource {
 				Description: "Signalflow program text for the chart. More info at \"https://developers.signalfx.com/docs/signalflow-overview\"",
 			},
 			"unit_prefix": &schema.Schema{
-				Type:        schema.TypeString,
-				Optional:    true,
-				Description: "(Metric by default) Must be \"Metric\" or \"Binary\"",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "(Metric by default) Must be \"Metric\" or \"Binary\"",
+				ValidateFunc: validateUnitPrefix,
 			},
 			"color_by": &schema.Schema{
-				Type:        schema.TypeString,
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "(Metric by default) Must be \"Metric\", \"Dimension\", or \"Scale\". \"Scale\" maps to Color by Value in the UI",
+				ValidateFunc: validateColorBy,
+			},
+			"color_scale": colorScaleSchema(),
+			"minimum_resolution": &schema.Schema{
+				Type:        schema.TypeInt,
 				Optional:    true,
-				Description: "(Metric by default) Must be \"Metric\" or \"Dimension\"",
+				Description: "The minimum resolution (in seconds) to use for computing the underlying program",
 			},
 			"max_delay": &schema.Schema{
 				Type:         schema.TypeInt,
@@ -62,10 +71,16 @@ func listChartResource() *schema.Resource {
 				Description:  "How long (in seconds) to wait for late datapoints",
 				ValidateFunc: validateMaxDelayValue,
 			},
+			"timezone": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateTimezone,
+				Description:  "Timezone in which the chart is rendered (e.g. UTC, Europe/Paris), whatever the timezone of the viewer",
+			},
 			"disable_sampling": &schema.Schema{
 				Type:        schema.TypeBool,
 				Optional:    true,
-				Description: "(false by default) If false, samples a subset of the output MTS, which improves UI performance",
+				Description: "(default_disable_sampling of the provider by default) If false, samples a subset of the output MTS, which improves UI performance",
 			},
 			"sort_by": &schema.Schema{
 				Type:         schema.TypeString,
@@ -79,11 +94,13 @@ func listChartResource() *schema.Resource {
 				Description: "How often (in seconds) to refresh the values of the list",
 			},
 			"legend_fields_to_hide": &schema.Schema{
-				Type:        schema.TypeSet,
-				Optional:    true,
-				Elem:        &schema.Schema{Type: schema.TypeString},
-				Description: "List of properties that shouldn't be displayed in the chart legend (i.e. dimension names)",
+				Type:          schema.TypeSet,
+				Optional:      true,
+				Elem:          &schema.Schema{Type: schema.TypeString},
+				ConflictsWith: []string{"legend_options_fields"},
+				Description:   "List of properties that shouldn't be displayed in the chart legend (i.e. dimension names)",
 			},
+			"legend_options_fields": legendOptionsFieldsSchema(),
 			"max_precision": &schema.Schema{
 				Type:        schema.TypeInt,
 				Optional:    true,
@@ -92,7 +109,7 @@ func listChartResource() *schema.Resource {
 			"secondary_visualization": &schema.Schema{
 				Type:         schema.TypeString,
 				Optional:     true,
-				Description:  "(false by default) What kind of secondary visualization to show (None, Radial, Linear, Sparkline)",
+				Description:  "What kind of secondary visualization to show next to the value of each row (None, Radial, Linear, Sparkline). The SignalFx default (Sparkline) is used if unset",
 				ValidateFunc: validateSecondaryVisualization,
 			},
 			"viz_options": &schema.Schema{
@@ -106,6 +123,11 @@ func listChartResource() *schema.Resource {
 							Required:    true,
 							Description: "The label used in the publish statement that displays the plot (metric time series data) you want to customize",
 						},
+						"display_name": &schema.Schema{
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Name of the plot in the chart, instead of its label",
+						},
 						"color": &schema.Schema{
 							Type:         schema.TypeString,
 							Optional:     true,
@@ -137,20 +159,22 @@ func listChartResource() *schema.Resource {
 		Read:   listchartRead,
 		Update: listchartUpdate,
 		Delete: listchartDelete,
+
+		CustomizeDiff: customdiff.All(validateProgramTextPublishes, validateColorByScale),
 	}
 }
 
 /*
   Use Resource object to construct json payload in order to create a list chart
 */
-func getPayloadListChart(d *schema.ResourceData) ([]byte, error) {
+func getPayloadListChart(d *schema.ResourceData, config *signalformConfig) ([]byte, error) {
 	payload := map[string]interface{}{
 		"name":        d.Get("name").(string),
 		"description": d.Get("description").(string),
 		"programText": d.Get("program_text").(string),
 	}
 
-	viz := getListChartOptions(d)
+	viz := getListChartOptions(d, config)
 	if legendOptions := getLegendOptions(d); len(legendOptions) > 0 {
 		viz["legendOptions"] = legendOptions
 	}
@@ -164,22 +188,35 @@ func getPayloadListChart(d *schema.ResourceData) ([]byte, error) {
 	return json.Marshal(payload)
 }
 
-func getListChartOptions(d *schema.ResourceData) map[string]interface{} {
+func getListChartOptions(d *schema.ResourceData, config *signalformConfig) map[string]interface{} {
 	viz := make(map[string]interface{})
 	viz["type"] = "List"
 	if val, ok := d.GetOk("unit_prefix"); ok {
 		viz["unitPrefix"] = val.(string)
 	}
 	if val, ok := d.GetOk("color_by"); ok {
-		viz["colorBy"] = val.(string)
+		if val == "Scale" {
+			if colorScaleOptions := getColorScaleOptions(d); len(colorScaleOptions) > 0 {
+				viz["colorBy"] = "Scale"
+				viz["colorScale2"] = colorScaleOptions
+			}
+		} else {
+			viz["colorBy"] = val.(string)
+		}
 	}
 
 	programOptions := make(map[string]interface{})
-	if val, ok := d.GetOk("max_delay"); ok {
-		programOptions["maxDelay"] = val.(int) * 1000
+	if val, ok := d.GetOk("minimum_resolution"); ok {
+		programOptions["minimumResolution"] = val.(int) * 1000
 	}
-	programOptions["disableSampling"] = d.Get("disable_sampling").(bool)
+	if maxDelay, ok := getMaxDelay(d.GetOkExists, config); ok {
+		programOptions["maxDelay"] = maxDelay * 1000
+	}
+	programOptions["disableSampling"] = getDisableSampling(d, config)
 	viz["programOptions"] = programOptions
+	if val, ok := d.GetOk("timezone"); ok {
+		viz["timezone"] = val.(string)
+	}
 
 	if sortBy, ok := d.GetOk("sort_by"); ok {
 		viz["sortBy"] = sortBy.(string)
@@ -202,35 +239,35 @@ func getListChartOptions(d *schema.ResourceData) map[string]interface{} {
 
 func listchartCreate(d *schema.ResourceData, meta interface{}) error {
 	config := meta.(*signalformConfig)
-	payload, err := getPayloadListChart(d)
+	payload, err := getPayloadListChart(d, config)
 	if err != nil {
 		return fmt.Errorf("Failed creating json payload: %s", err.Error())
 	}
 
-	return resourceCreate(CHART_API_URL, config.AuthToken, payload, d)
+	return resourceCreate(CHART_API_URL, config, payload, d)
 }
 
 func listchartRead(d *schema.ResourceData, meta interface{}) error {
 	config := meta.(*signalformConfig)
 	url := fmt.Sprintf("%s/%s", CHART_API_URL, d.Id())
 
-	return resourceRead(url, config.AuthToken, d)
+	return resourceRead(url, config, d)
 }
 
 func listchartUpdate(d *schema.ResourceData, meta interface{}) error {
 	config := meta.(*signalformConfig)
-	payload, err := getPayloadListChart(d)
+	payload, err := getPayloadListChart(d, config)
 	if err != nil {
 		return fmt.Errorf("Failed creating json payload: %s", err.Error())
 	}
 	url := fmt.Sprintf("%s/%s", CHART_API_URL, d.Id())
 
-	return resourceUpdate(url, config.AuthToken, payload, d)
+	return resourceUpdate(url, config, payload, d)
 }
 
 func listchartDelete(d *schema.ResourceData, meta interface{}) error {
 	config := meta.(*signalformConfig)
 	url := fmt.Sprintf("%s/%s", CHART_API_URL, d.Id())
 
-	return resourceDelete(url, config.AuthToken, d)
+	return resourceDelete(url, config, d)
 }
@@ -0,0 +1,51 @@
+package signalform
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGetListChartOptionsSecondaryVisualization(t *testing.T) {
+	d := listChartResource().TestResourceData()
+	viz := getListChartOptions(d, &signalformConfig{})
+	_, ok := viz["secondaryVisualization"]
+	assert.False(t, ok)
+
+	d.Set("secondary_visualization", "Radial")
+	viz = getListChartOptions(d, &signalformConfig{})
+	assert.Equal(t, "Radial", viz["secondaryVisualization"])
+}
+
+func TestGetListChartOptionsSortBy(t *testing.T) {
+	d := listChartResource().TestResourceData()
+	d.Set("sort_by", "-value")
+	viz := getListChartOptions(d, &signalformConfig{})
+	assert.Equal(t, "-value", viz["sortBy"])
+}
+
+func TestGetListChartOptionsColorScale(t *testing.T) {
+	d := listChartResource().TestResourceData()
+	d.Set("color_by", "Scale")
+	d.Set("color_scale", []interface{}{
+		map[string]interface{}{"gt": 500.0, "color": "magenta"},
+		map[string]interface{}{"lte": 500.0, "color": "green"},
+	})
+	viz := getListChartOptions(d, &signalformConfig{})
+	assert.Equal(t, "Scale", viz["colorBy"])
+	assert.Equal(t, 2, len(viz["colorScale2"].([]interface{})))
+}
+
+func TestGetListChartOptionsTimezone(t *testing.T) {
+	d := listChartResource().TestResourceData()
+	d.Set("timezone", "America/New_York")
+	viz := getListChartOptions(d, &signalformConfig{})
+	assert.Equal(t, "America/New_York", viz["timezone"])
+	assert.NotContains(t, viz["programOptions"], "timezone")
+}
+
+func TestGetListChartOptionsResolution(t *testing.T) {
+	d := listChartResource().TestResourceData()
+	d.Set("minimum_resolution", 300)
+	viz := getListChartOptions(d, &signalformConfig{})
+	assert.Equal(t, 300000, viz["programOptions"].(map[string]interface{})["minimumResolution"])
+}
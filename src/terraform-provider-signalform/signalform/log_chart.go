@@ -0,0 +1,266 @@
+package signalform
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"terraform-provider-signalform/internal/sfxtime"
+)
+
+/*
+  Schema shared by the logs based charts
+*/
+func logChartSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"synced": &schema.Schema{
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     true,
+			Description: "Whether the resource in SignalForm and SignalFx are identical or not. Used internally for syncing.",
+		},
+		"last_updated": &schema.Schema{
+			Type:        schema.TypeFloat,
+			Computed:    true,
+			Description: "Latest timestamp the resource was updated",
+		},
+		"resource_url": &schema.Schema{
+			Type:        schema.TypeString,
+			Optional:    true,
+			Default:     CHART_URL,
+			Description: "API URL of the chart",
+		},
+		"url": &schema.Schema{
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "URL of the chart",
+		},
+		"name": &schema.Schema{
+			Type:        schema.TypeString,
+			Required:    true,
+			Description: "Name of the chart",
+		},
+		"description": &schema.Schema{
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "Description of the chart (Optional)",
+		},
+		"program_text": &schema.Schema{
+			Type:        schema.TypeString,
+			Required:    true,
+			Description: "Signalflow program text for the chart, querying logs with the logs() function",
+		},
+		"default_connection": &schema.Schema{
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "The connection that the chart uses to fetch data. This could be Splunk Enterprise, Splunk Enterprise Cloud or Observability Cloud",
+		},
+		"time_range": &schema.Schema{
+			Type:          schema.TypeString,
+			Optional:      true,
+			ValidateFunc:  sfxtime.Validate,
+			Description:   "From when to display data. SignalFx time syntax (e.g. -5m, -1h)",
+			ConflictsWith: []string{"start_time", "end_time"},
+		},
+		"start_time": &schema.Schema{
+			Type:          schema.TypeInt,
+			Optional:      true,
+			Description:   "Seconds since epoch to start the visualization",
+			ConflictsWith: []string{"time_range"},
+		},
+		"end_time": &schema.Schema{
+			Type:          schema.TypeInt,
+			Optional:      true,
+			Description:   "Seconds since epoch to end the visualization",
+			ConflictsWith: []string{"time_range"},
+		},
+	}
+}
+
+func logViewChartResource() *schema.Resource {
+	logSchema := logChartSchema()
+	logSchema["column"] = &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		Description: "Columns to display, in order",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"name": &schema.Schema{
+					Type:        schema.TypeString,
+					Required:    true,
+					Description: "Name of the log field to display in the column",
+				},
+			},
+		},
+	}
+	logSchema["sort_options"] = &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		Description: "Sorting of the logs, by order of precedence",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"field": &schema.Schema{
+					Type:        schema.TypeString,
+					Required:    true,
+					Description: "Name of the log field to sort by",
+				},
+				"descending": &schema.Schema{
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     false,
+					Description: "(false by default) Whether to sort in descending order",
+				},
+			},
+		},
+	}
+
+	return &schema.Resource{
+		Schema: logSchema,
+
+		Create: logviewchartCreate,
+		Read:   logchartRead,
+		Update: logviewchartUpdate,
+		Delete: logchartDelete,
+
+		CustomizeDiff: validateProgramTextPublishes,
+	}
+}
+
+func logTimelineChartResource() *schema.Resource {
+	return &schema.Resource{
+		Schema: logChartSchema(),
+
+		Create: logtimelinechartCreate,
+		Read:   logchartRead,
+		Update: logtimelinechartUpdate,
+		Delete: logchartDelete,
+
+		CustomizeDiff: validateProgramTextPublishes,
+	}
+}
+
+/*
+  Use Resource object to construct json payload in order to create a logs based chart of the given type
+*/
+func getPayloadLogChart(d *schema.ResourceData, chartType string) ([]byte, error) {
+	payload := map[string]interface{}{
+		"name":        d.Get("name").(string),
+		"description": d.Get("description").(string),
+		"programText": d.Get("program_text").(string),
+	}
+
+	viz := getLogChartOptions(d, chartType)
+	if len(viz) > 0 {
+		payload["options"] = viz
+	}
+
+	return json.Marshal(payload)
+}
+
+func getLogChartOptions(d *schema.ResourceData, chartType string) map[string]interface{} {
+	viz := make(map[string]interface{})
+	viz["type"] = chartType
+	if val, ok := d.GetOk("default_connection"); ok {
+		viz["defaultConnection"] = val.(string)
+	}
+
+	if tf_columns, ok := d.GetOk("column"); ok {
+		tf_columns := tf_columns.([]interface{})
+		columns := make([]map[string]interface{}, len(tf_columns))
+		for i, tf_column := range tf_columns {
+			tf_column := tf_column.(map[string]interface{})
+			columns[i] = map[string]interface{}{
+				"name": tf_column["name"].(string),
+			}
+		}
+		viz["columns"] = columns
+	}
+
+	if tf_sort_options, ok := d.GetOk("sort_options"); ok {
+		tf_sort_options := tf_sort_options.([]interface{})
+		sortOptions := make([]map[string]interface{}, len(tf_sort_options))
+		for i, tf_sort_option := range tf_sort_options {
+			tf_sort_option := tf_sort_option.(map[string]interface{})
+			sortOptions[i] = map[string]interface{}{
+				"field":      tf_sort_option["field"].(string),
+				"descending": tf_sort_option["descending"].(bool),
+			}
+		}
+		viz["sortOptions"] = sortOptions
+	}
+
+	timeMap := make(map[string]interface{})
+	if val, ok := d.GetOk("time_range"); ok {
+		if ms, err := sfxtime.ToMilliseconds(val.(string)); err == nil {
+			timeMap["range"] = ms
+			timeMap["type"] = "relative"
+		}
+	}
+	if val, ok := d.GetOk("start_time"); ok {
+		timeMap["start"] = val.(int) * 1000
+		timeMap["type"] = "absolute"
+		if val, ok := d.GetOk("end_time"); ok {
+			timeMap["end"] = val.(int) * 1000
+		}
+	}
+	if len(timeMap) > 0 {
+		viz["time"] = timeMap
+	}
+
+	return viz
+}
+
+func logviewchartCreate(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*signalformConfig)
+	payload, err := getPayloadLogChart(d, "LogsChart")
+	if err != nil {
+		return fmt.Errorf("Failed creating json payload: %s", err.Error())
+	}
+
+	return resourceCreate(CHART_API_URL, config, payload, d)
+}
+
+func logviewchartUpdate(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*signalformConfig)
+	payload, err := getPayloadLogChart(d, "LogsChart")
+	if err != nil {
+		return fmt.Errorf("Failed creating json payload: %s", err.Error())
+	}
+	url := fmt.Sprintf("%s/%s", CHART_API_URL, d.Id())
+
+	return resourceUpdate(url, config, payload, d)
+}
+
+func logtimelinechartCreate(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*signalformConfig)
+	payload, err := getPayloadLogChart(d, "LogsTimeSeriesChart")
+	if err != nil {
+		return fmt.Errorf("Failed creating json payload: %s", err.Error())
+	}
+
+	return resourceCreate(CHART_API_URL, config, payload, d)
+}
+
+func logtimelinechartUpdate(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*signalformConfig)
+	payload, err := getPayloadLogChart(d, "LogsTimeSeriesChart")
+	if err != nil {
+		return fmt.Errorf("Failed creating json payload: %s", err.Error())
+	}
+	url := fmt.Sprintf("%s/%s", CHART_API_URL, d.Id())
+
+	return resourceUpdate(url, config, payload, d)
+}
+
+func logchartRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*signalformConfig)
+	url := fmt.Sprintf("%s/%s", CHART_API_URL, d.Id())
+
+	return resourceRead(url, config, d)
+}
+
+func logchartDelete(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*signalformConfig)
+	url := fmt.Sprintf("%s/%s", CHART_API_URL, d.Id())
+	return resourceDelete(url, config, d)
+}
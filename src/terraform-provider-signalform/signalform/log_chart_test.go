@@ -0,0 +1,61 @@
+package signalform
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGetPayloadLogViewChart(t *testing.T) {
+	d := logViewChartResource().TestResourceData()
+	d.Set("name", "Errors")
+	d.Set("description", "Error logs of the API")
+	d.Set("program_text", "logs(filter=field('level') == 'error').publish()")
+	d.Set("default_connection", "Observability Cloud")
+	d.Set("time_range", "-1h")
+	d.Set("column", []interface{}{
+		map[string]interface{}{"name": "severity"},
+		map[string]interface{}{"name": "message"},
+	})
+	d.Set("sort_options", []interface{}{
+		map[string]interface{}{"field": "timestamp", "descending": true},
+	})
+
+	payload, err := getPayloadLogChart(d, "LogsChart")
+	assert.Nil(t, err)
+	var chart map[string]interface{}
+	assert.Nil(t, json.Unmarshal(payload, &chart))
+	assert.Equal(t, "Errors", chart["name"])
+	assert.Equal(t, "Error logs of the API", chart["description"])
+	assert.Equal(t, "logs(filter=field('level') == 'error').publish()", chart["programText"])
+	options := chart["options"].(map[string]interface{})
+	assert.Equal(t, "LogsChart", options["type"])
+	assert.Equal(t, "Observability Cloud", options["defaultConnection"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"name": "severity"},
+		map[string]interface{}{"name": "message"},
+	}, options["columns"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"field": "timestamp", "descending": true},
+	}, options["sortOptions"])
+	assert.Equal(t, map[string]interface{}{"type": "relative", "range": 3600000.0}, options["time"])
+}
+
+func TestGetPayloadLogTimelineChart(t *testing.T) {
+	d := logTimelineChartResource().TestResourceData()
+	d.Set("name", "Errors over time")
+	d.Set("program_text", "logs(filter=field('level') == 'error').publish()")
+	d.Set("start_time", 1500000000)
+	d.Set("end_time", 1500003600)
+
+	payload, err := getPayloadLogChart(d, "LogsTimeSeriesChart")
+	assert.Nil(t, err)
+	var chart map[string]interface{}
+	assert.Nil(t, json.Unmarshal(payload, &chart))
+	options := chart["options"].(map[string]interface{})
+	assert.Equal(t, "LogsTimeSeriesChart", options["type"])
+	assert.NotContains(t, options, "defaultConnection")
+	assert.NotContains(t, options, "columns")
+	assert.NotContains(t, options, "sortOptions")
+	assert.Equal(t, map[string]interface{}{"type": "absolute", "start": 1500000000000.0, "end": 1500003600000.0}, options["time"])
+}
@@ -0,0 +1,108 @@
+package signalform
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+const METRIC_API_URL = "https://api.signalfx.com/v2/metric"
+
+/*
+  Data source searching metrics by name pattern, so that plans fail early when the metrics a dashboard or
+  detector depends on are not reported yet in the organization
+*/
+func metricsDataSource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Name pattern of the metrics, * matching any characters (e.g. cpu.*)",
+			},
+			"required": &schema.Schema{
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Metric names which must be found. At least one metric must match name if not set",
+			},
+			"limit": &schema.Schema{
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     1000,
+				Description: "(1000 by default) Maximum number of metrics to search",
+			},
+			"names": &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Sorted names of the metrics found",
+			},
+		},
+
+		Read: metricsDataSourceRead,
+	}
+}
+
+func metricsDataSourceRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*signalformConfig)
+	pattern := d.Get("name").(string)
+	required := make([]string, 0)
+	for _, name := range d.Get("required").([]interface{}) {
+		required = append(required, name.(string))
+	}
+
+	query := url.Values{
+		"query": []string{"name:" + pattern},
+		"limit": []string{strconv.Itoa(d.Get("limit").(int))},
+	}
+	metrics, err := searchSignalFxObjects(METRIC_API_URL, query, config)
+	if err != nil {
+		return fmt.Errorf("Searching metrics: %s", err.Error())
+	}
+	names := getMetricNames(metrics)
+	if err := checkMetricNames(pattern, names, required); err != nil {
+		return err
+	}
+
+	d.SetId(pattern)
+	return d.Set("names", names)
+}
+
+func getMetricNames(metrics []map[string]interface{}) []string {
+	names := make([]string, 0)
+	for _, metric := range metrics {
+		if name, ok := metric["name"].(string); ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func checkMetricNames(pattern string, names []string, required []string) error {
+	if len(required) == 0 {
+		if len(names) == 0 {
+			return fmt.Errorf("No metric matches %s", pattern)
+		}
+		return nil
+	}
+	found := make(map[string]bool)
+	for _, name := range names {
+		found[name] = true
+	}
+	missing := make([]string, 0)
+	for _, name := range required {
+		if !found[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("Metrics not found among those matching %s: %s", pattern, strings.Join(missing, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package signalform
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGetMetricNames(t *testing.T) {
+	metrics := []map[string]interface{}{
+		map[string]interface{}{"name": "cpu.utilization"},
+		map[string]interface{}{"name": "cpu.idle"},
+	}
+	assert.Equal(t, []string{"cpu.idle", "cpu.utilization"}, getMetricNames(metrics))
+}
+
+func TestCheckMetricNames(t *testing.T) {
+	names := []string{"cpu.idle", "cpu.utilization"}
+	assert.Nil(t, checkMetricNames("cpu.*", names, []string{}))
+	assert.Nil(t, checkMetricNames("cpu.*", names, []string{"cpu.idle"}))
+
+	err := checkMetricNames("cpu.*", []string{}, []string{})
+	assert.Contains(t, err.Error(), "No metric matches cpu.*")
+
+	err = checkMetricNames("cpu.*", names, []string{"cpu.idle", "cpu.steal", "cpu.user"})
+	assert.Contains(t, err.Error(), "Metrics not found among those matching cpu.*: cpu.steal, cpu.user")
+}
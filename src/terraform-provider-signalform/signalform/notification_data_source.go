@@ -0,0 +1,42 @@
+package signalform
+
+import (
+	"strconv"
+
+	"github.com/hashicorp/terraform/helper/hashcode"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+/*
+  Data source building a notification string from its fields, for notifications lists which would
+  otherwise concatenate them by hand (e.g. "PagerDuty,${var.credential_id}")
+*/
+func notificationDataSource() *schema.Resource {
+	notificationFields := notificationSchema()
+	notificationFields["notification"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Sensitive:   true,
+		Description: "The notification string, e.g. Slack,<credential_id>,<channel>. Sensitive, as it holds the secret of webhooks",
+	}
+	return &schema.Resource{
+		Schema: notificationFields,
+
+		Read: notificationDataSourceRead,
+	}
+}
+
+func notificationDataSourceRead(d *schema.ResourceData, meta interface{}) error {
+	tf_notification := make(map[string]interface{})
+	for name := range notificationSchema() {
+		tf_notification[name] = d.Get(name)
+	}
+	notification, err := getNotificationString(tf_notification)
+	if err != nil {
+		return err
+	}
+
+	// The ID must not reveal the secret of webhooks
+	d.SetId(strconv.Itoa(hashcode.String(notification)))
+	return d.Set("notification", notification)
+}
@@ -0,0 +1,34 @@
+package signalform
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNotificationDataSourceRead(t *testing.T) {
+	d := notificationDataSource().TestResourceData()
+	d.Set("type", "Slack")
+	d.Set("credential_id", "C1")
+	d.Set("channel", "alerts")
+	assert.Nil(t, notificationDataSourceRead(d, nil))
+	assert.Equal(t, "Slack,C1,alerts", d.Get("notification"))
+	assert.NotContains(t, d.Id(), "C1")
+
+	d = notificationDataSource().TestResourceData()
+	d.Set("type", "Slack")
+	d.Set("credential_id", "C1")
+	err := notificationDataSourceRead(d, nil)
+	assert.Contains(t, err.Error(), "field 3 of Slack notifications must not be empty")
+}
+
+func TestNotificationDataSourceWebhookSecret(t *testing.T) {
+	d := notificationDataSource().TestResourceData()
+	d.Set("type", "Webhook")
+	d.Set("secret", "s3cr3t")
+	d.Set("url", "https://hooks.yelp.com/alerts")
+	assert.Nil(t, notificationDataSourceRead(d, nil))
+	assert.Equal(t, "Webhook,s3cr3t,https://hooks.yelp.com/alerts", d.Get("notification"))
+	assert.NotContains(t, d.Id(), "s3cr3t")
+	// The notification holds the secret, so it must not show up in plans
+	assert.True(t, notificationDataSource().Schema["notification"].Sensitive)
+}
@@ -0,0 +1,139 @@
+package signalform
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/hashcode"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+const TEAM_API_URL = "https://api.signalfx.com/v2/team"
+
+var emailRegexp = regexp.MustCompile(`^[^@\s,]+@[^@\s,]+\.[^@\s,]+$`)
+
+/*
+  Data source resolving the notification strings of a detector (e.g. "PagerDuty,credId") against SignalFx,
+  so that routing can be verified before the first real alert.
+*/
+func notificationRoutingDataSource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"notifications": &schema.Schema{
+				Type:        schema.TypeList,
+				Required:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Notification strings to resolve, in the same format as the notifications of a detector rule",
+			},
+			"targets": &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Description of who or what would be notified, one per notification",
+			},
+		},
+
+		Read: notificationRoutingRead,
+	}
+}
+
+func notificationRoutingRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*signalformConfig)
+	notifications := d.Get("notifications").([]interface{})
+
+	targets := make([]string, len(notifications))
+	failures := make([]string, 0)
+	for i, notification := range notifications {
+		target, err := resolveNotificationTarget(notification.(string), config)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %s", notification, err.Error()))
+			continue
+		}
+		targets[i] = target
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("Failed resolving notifications:\n%s", strings.Join(failures, "\n"))
+	}
+
+	d.SetId(fmt.Sprintf("%d", hashcode.String(strings.Join(targets, "\n"))))
+	return d.Set("targets", targets)
+}
+
+/*
+  Checks that a notification string points to something which exists (integration, team) or is well formed
+  (email, webhook URL), and returns a description of what would be notified.
+*/
+func resolveNotificationTarget(notification string, config *signalformConfig) (string, error) {
+	vars := strings.Split(notification, ",")
+	if len(vars) < 2 || vars[1] == "" {
+		return "", fmt.Errorf("missing notification target")
+	}
+
+	switch vars[0] {
+	case "Email":
+		if !emailRegexp.MatchString(vars[1]) {
+			return "", fmt.Errorf("%s is not a valid email address", vars[1])
+		}
+		return fmt.Sprintf("Email to %s", vars[1]), nil
+	case "PagerDuty", "Slack":
+		integration, err := getSignalFxObject(fmt.Sprintf("%s/%s", INTEGRATION_API_URL, vars[1]), config)
+		if err != nil {
+			return "", fmt.Errorf("integration %s: %s", vars[1], err.Error())
+		}
+		if integration["type"] != vars[0] {
+			return "", fmt.Errorf("integration %s is a %s integration, not %s", vars[1], integration["type"], vars[0])
+		}
+		if enabled, ok := integration["enabled"].(bool); ok && !enabled {
+			return "", fmt.Errorf("integration %s (%s) is disabled", vars[1], integration["name"])
+		}
+		if vars[0] == "Slack" {
+			if len(vars) < 3 || vars[2] == "" {
+				return "", fmt.Errorf("missing Slack channel")
+			}
+			return fmt.Sprintf("Slack channel %s through %s", vars[2], integration["name"]), nil
+		}
+		return fmt.Sprintf("PagerDuty service of %s", integration["name"]), nil
+	case "Webhook":
+		if len(vars) < 3 {
+			return "", fmt.Errorf("missing webhook URL")
+		}
+		if u, err := url.Parse(vars[2]); err != nil || u.Scheme == "" || u.Host == "" {
+			return "", fmt.Errorf("%s is not a valid webhook URL", vars[2])
+		}
+		return fmt.Sprintf("Webhook %s", vars[2]), nil
+	case "Team", "TeamEmail":
+		team, err := getSignalFxObject(fmt.Sprintf("%s/%s", TEAM_API_URL, vars[1]), config)
+		if err != nil {
+			return "", fmt.Errorf("team %s: %s", vars[1], err.Error())
+		}
+		if vars[0] == "TeamEmail" {
+			return fmt.Sprintf("Email to members of team %s", team["name"]), nil
+		}
+		return fmt.Sprintf("Notification policy of team %s", team["name"]), nil
+	}
+	return "", fmt.Errorf("unknown notification type %s", vars[0])
+}
+
+/*
+  Fetches a SignalFx object, failing if it does not exist
+*/
+func getSignalFxObject(url string, config *signalformConfig) (map[string]interface{}, error) {
+	status_code, resp_body, err := sendRequest("GET", url, config, nil)
+	if err != nil {
+		return nil, err
+	}
+	if status_code == 404 {
+		return nil, fmt.Errorf("not found")
+	}
+	if status_code != 200 {
+		return nil, fmt.Errorf("SignalFx returned status %d: \n%s", status_code, resp_body)
+	}
+	object := map[string]interface{}{}
+	if err := json.Unmarshal(resp_body, &object); err != nil {
+		return nil, fmt.Errorf("Failed unmarshaling: %s", err.Error())
+	}
+	return object, nil
+}
@@ -0,0 +1,33 @@
+package signalform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveNotificationTargetEmail(t *testing.T) {
+	target, err := resolveNotificationTarget("Email,test@yelp.com", &signalformConfig{AuthToken: "token"})
+	assert.Nil(t, err)
+	assert.Equal(t, "Email to test@yelp.com", target)
+
+	_, err = resolveNotificationTarget("Email,test.yelp.com", &signalformConfig{AuthToken: "token"})
+	assert.Contains(t, err.Error(), "not a valid email address")
+}
+
+func TestResolveNotificationTargetWebhook(t *testing.T) {
+	target, err := resolveNotificationTarget("Webhook,secret,https://foo.bar.com?user=test", &signalformConfig{AuthToken: "token"})
+	assert.Nil(t, err)
+	assert.Equal(t, "Webhook https://foo.bar.com?user=test", target)
+
+	_, err = resolveNotificationTarget("Webhook,secret,foo", &signalformConfig{AuthToken: "token"})
+	assert.Contains(t, err.Error(), "not a valid webhook URL")
+}
+
+func TestResolveNotificationTargetMalformed(t *testing.T) {
+	_, err := resolveNotificationTarget("PagerDuty", &signalformConfig{AuthToken: "token"})
+	assert.Contains(t, err.Error(), "missing notification target")
+
+	_, err = resolveNotificationTarget("Carrier pigeon,home", &signalformConfig{AuthToken: "token"})
+	assert.Contains(t, err.Error(), "unknown notification type")
+}
@@ -0,0 +1,96 @@
+package signalform
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+/*
+  Applies modify to the list held by field of the object at url, and writes the object back. Used by the
+  resources managing a single entry of another object, e.g. a chart of a dashboard or a mirror in the
+  dashboardConfigs of a dashboard group. The object is read, modified and written as a whole, so
+  modifications holding the same lock never interleave.
+*/
+func updateObjectEntries(lock *sync.Mutex, url string, objectName string, config *signalformConfig, field string, modify func([]interface{}) ([]interface{}, error)) error {
+	lock.Lock()
+	defer lock.Unlock()
+
+	object, err := getSignalFxObject(url, config)
+	if err != nil {
+		return fmt.Errorf("%s: %s", objectName, err.Error())
+	}
+	entries, _ := object[field].([]interface{})
+	entries, err = modify(entries)
+	if err != nil {
+		return err
+	}
+	object[field] = entries
+	for _, readOnlyField := range readOnlyFields {
+		delete(object, readOnlyField)
+	}
+
+	payload, err := json.Marshal(object)
+	if err != nil {
+		return fmt.Errorf("Failed creating json payload: %s", err.Error())
+	}
+	status_code, resp_body, err := sendRequest("PUT", url, config, payload)
+	if err != nil {
+		return err
+	}
+	if status_code != 200 {
+		return fmt.Errorf("For the %s SignalFx returned status %d: \n%s", objectName, status_code, resp_body)
+	}
+	return nil
+}
+
+/*
+  Reads the entry of the list held by field of the object at url whose key is value. The entry is nil
+  when either the object or the entry does not exist anymore.
+*/
+func readObjectEntry(url string, objectName string, config *signalformConfig, field string, key string, value string) (map[string]interface{}, error) {
+	status_code, resp_body, err := sendRequest("GET", url, config, nil)
+	if err != nil {
+		return nil, err
+	}
+	if status_code == 404 {
+		return nil, nil
+	}
+	if status_code != 200 {
+		return nil, fmt.Errorf("For the %s SignalFx returned status %d: \n%s", objectName, status_code, resp_body)
+	}
+	object := map[string]interface{}{}
+	if err := json.Unmarshal(resp_body, &object); err != nil {
+		return nil, fmt.Errorf("Failed unmarshaling the %s: %s", objectName, err.Error())
+	}
+
+	entries, _ := object[field].([]interface{})
+	if index := findObjectEntry(entries, key, value); index != -1 {
+		return entries[index].(map[string]interface{}), nil
+	}
+	return nil, nil
+}
+
+/*
+  Returns the index of the entry whose key is value, -1 if there is none
+*/
+func findObjectEntry(entries []interface{}, key string, value string) int {
+	for i, entry := range entries {
+		if entry, ok := entry.(map[string]interface{}); ok && entry[key] == value {
+			return i
+		}
+	}
+	return -1
+}
+
+/*
+  Splits an ID of the form <object ID>/<entry key>, as used to import the entries
+*/
+func parseObjectEntryId(id string, format string) (string, string, error) {
+	ids := strings.Split(id, "/")
+	if len(ids) != 2 || ids[0] == "" || ids[1] == "" {
+		return "", "", fmt.Errorf("Invalid ID %s, expected %s", id, format)
+	}
+	return ids[0], ids[1], nil
+}
@@ -0,0 +1,87 @@
+package signalform
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateObjectEntries(t *testing.T) {
+	var put map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "PUT" {
+			body, _ := ioutil.ReadAll(r.Body)
+			json.Unmarshal(body, &put)
+			w.WriteHeader(200)
+			return
+		}
+		w.WriteHeader(200)
+		w.Write([]byte(`{"id": "dash", "name": "Dashboard", "lastUpdated": 1, "charts": [{"chartId": "first"}]}`))
+	}))
+	defer server.Close()
+
+	var lock sync.Mutex
+	err := updateObjectEntries(&lock, server.URL, "dashboard dash", &signalformConfig{AuthToken: "token"}, "charts", func(charts []interface{}) ([]interface{}, error) {
+		return append(charts, map[string]interface{}{"chartId": "second"}), nil
+	})
+	assert.Nil(t, err)
+	// The read-only fields are not sent back
+	assert.Equal(t, map[string]interface{}{
+		"name": "Dashboard",
+		"charts": []interface{}{
+			map[string]interface{}{"chartId": "first"},
+			map[string]interface{}{"chartId": "second"},
+		},
+	}, put)
+}
+
+func TestReadObjectEntry(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(404)
+			return
+		}
+		w.WriteHeader(200)
+		w.Write([]byte(`{"dashboardConfigs": [{"dashboardId": "first"}, {"dashboardId": "second", "nameOverride": "Mirror"}]}`))
+	}))
+	defer server.Close()
+
+	entry, err := readObjectEntry(server.URL, "dashboard group group", &signalformConfig{AuthToken: "token"}, "dashboardConfigs", "dashboardId", "second")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"dashboardId": "second", "nameOverride": "Mirror"}, entry)
+
+	entry, err = readObjectEntry(server.URL, "dashboard group group", &signalformConfig{AuthToken: "token"}, "dashboardConfigs", "dashboardId", "third")
+	assert.Nil(t, err)
+	assert.Nil(t, entry)
+
+	entry, err = readObjectEntry(server.URL+"/missing", "dashboard group group", &signalformConfig{AuthToken: "token"}, "dashboardConfigs", "dashboardId", "second")
+	assert.Nil(t, err)
+	assert.Nil(t, entry)
+}
+
+func TestFindObjectEntry(t *testing.T) {
+	charts := []interface{}{
+		map[string]interface{}{"chartId": "first"},
+		map[string]interface{}{"chartId": "second"},
+	}
+	assert.Equal(t, 0, findObjectEntry(charts, "chartId", "first"))
+	assert.Equal(t, 1, findObjectEntry(charts, "chartId", "second"))
+	assert.Equal(t, -1, findObjectEntry(charts, "chartId", "third"))
+}
+
+func TestParseObjectEntryId(t *testing.T) {
+	objectId, key, err := parseObjectEntryId("dash/chart", "<dashboard ID>/<chart ID>")
+	assert.Nil(t, err)
+	assert.Equal(t, "dash", objectId)
+	assert.Equal(t, "chart", key)
+
+	for _, id := range []string{"dash", "dash/", "/chart", "dash/chart/other"} {
+		_, _, err := parseObjectEntryId(id, "<dashboard ID>/<chart ID>")
+		assert.EqualError(t, err, "Invalid ID "+id+", expected <dashboard ID>/<chart ID>")
+	}
+}
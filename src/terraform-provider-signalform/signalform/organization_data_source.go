@@ -0,0 +1,72 @@
+package signalform
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+const ORGANIZATION_API_URL = "https://api.signalfx.com/v2/organization"
+
+/*
+  Data source describing the organization of the configured token, e.g. to check that a workspace uses the
+  token of the right organization
+*/
+func organizationDataSource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Name of the organization",
+			},
+			"realm": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Realm of the organization (e.g. us0), from the API URL the provider uses",
+			},
+			"app_url": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Base URL of the SignalFx application, custom_app_url if set in the provider",
+			},
+		},
+
+		Read: organizationDataSourceRead,
+	}
+}
+
+func organizationDataSourceRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*signalformConfig)
+	organization, err := getSignalFxObject(ORGANIZATION_API_URL, config)
+	if err != nil {
+		return fmt.Errorf("Reading the organization: %s", err.Error())
+	}
+
+	id, _ := organization["id"].(string)
+	d.SetId(id)
+	d.Set("name", organization["organizationName"])
+	d.Set("realm", getRealm(ORGANIZATION_API_URL))
+	appUrl := DEFAULT_APP_URL
+	if config.CustomAppURL != "" {
+		appUrl = strings.TrimRight(config.CustomAppURL, "/")
+	}
+	return d.Set("app_url", appUrl)
+}
+
+/*
+  The API of a realm is served by api.<realm>.signalfx.com, except for us0 whose API is api.signalfx.com
+*/
+func getRealm(apiUrl string) string {
+	parsed, err := url.Parse(apiUrl)
+	if err != nil {
+		return ""
+	}
+	parts := strings.Split(parsed.Hostname(), ".")
+	if len(parts) == 4 && parts[0] == "api" {
+		return parts[1]
+	}
+	return "us0"
+}
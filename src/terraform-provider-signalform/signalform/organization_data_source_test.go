@@ -0,0 +1,12 @@
+package signalform
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGetRealm(t *testing.T) {
+	assert.Equal(t, "us0", getRealm(ORGANIZATION_API_URL))
+	assert.Equal(t, "eu0", getRealm("https://api.eu0.signalfx.com/v2/organization"))
+	assert.Equal(t, "us1", getRealm("https://api.us1.signalfx.com/v2/organization"))
+}
@@ -0,0 +1,9 @@
+package signalform
+
+import (
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func pagerDutyIntegrationResource() *schema.Resource {
+	return typedIntegrationResource("PagerDuty", "PagerDuty API key")
+}
@@ -0,0 +1,137 @@
+package signalform
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+/*
+  Schema of the permissions block shared by dashboards and dashboard groups
+*/
+func permissionsSchema(objectName string) *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Description: fmt.Sprintf("Who can read and write the %s. Everyone in the organization can if not set", objectName),
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"acl": &schema.Schema{
+					Type:        schema.TypeList,
+					Optional:    true,
+					Description: "Principals allowed to access the object and their actions",
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"principal_id": &schema.Schema{
+								Type:        schema.TypeString,
+								Required:    true,
+								Description: "ID of the user, team or organization",
+							},
+							"principal_type": &schema.Schema{
+								Type:         schema.TypeString,
+								Required:     true,
+								ValidateFunc: validatePrincipalType,
+								Description:  "Type of the principal. Must be one of USER, TEAM or ORG",
+							},
+							"actions": &schema.Schema{
+								Type:        schema.TypeSet,
+								Required:    true,
+								Elem:        &schema.Schema{Type: schema.TypeString, ValidateFunc: validatePermissionAction},
+								Description: "Actions the principal is allowed to do. Must be READ, WRITE or both",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+/*
+  Use Resource object to construct the authorizedWriters object of the payload. Both lists are always
+  sent, so that removing every writer from the configuration unlocks the object again.
+*/
+func getAuthorizedWriters(d *schema.ResourceData) map[string]interface{} {
+	return map[string]interface{}{
+		"users": d.Get("authorized_writer_users").(*schema.Set).List(),
+		"teams": d.Get("authorized_writer_teams").(*schema.Set).List(),
+	}
+}
+
+/*
+  Use Resource object to construct the permissions object of the payload. The ACL is always sent, empty
+  when there is no permissions block, so that removing the block opens the object to everyone again.
+*/
+func getPermissions(d *schema.ResourceData) map[string]interface{} {
+	acl := make([]map[string]interface{}, 0)
+	if tf_permissions, ok := d.GetOk("permissions"); ok {
+		if tf_permission, ok := tf_permissions.([]interface{})[0].(map[string]interface{}); ok {
+			for _, entry := range tf_permission["acl"].([]interface{}) {
+				entry := entry.(map[string]interface{})
+				acl = append(acl, map[string]interface{}{
+					"principalId":   entry["principal_id"].(string),
+					"principalType": entry["principal_type"].(string),
+					"actions":       entry["actions"].(*schema.Set).List(),
+				})
+			}
+		}
+	}
+	return map[string]interface{}{
+		"acl": acl,
+	}
+}
+
+/*
+  Reflects the ACL of a dashboard or dashboard group in the state, so that permissions changed in the UI
+  show up in the plan. An empty ACL is only set when there is a permissions block, as it is the same as none.
+*/
+func permissionsAPIToState(object map[string]interface{}, d *schema.ResourceData) error {
+	permissions, _ := object["permissions"].(map[string]interface{})
+	entries, _ := permissions["acl"].([]interface{})
+	if _, ok := d.GetOk("permissions"); !ok && len(entries) == 0 {
+		return nil
+	}
+
+	acl := make([]interface{}, 0, len(entries))
+	for _, entry := range entries {
+		entry, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		actions, _ := entry["actions"].([]interface{})
+		acl = append(acl, map[string]interface{}{
+			"principal_id":   entry["principalId"],
+			"principal_type": entry["principalType"],
+			"actions":        actions,
+		})
+	}
+	return d.Set("permissions", []interface{}{
+		map[string]interface{}{"acl": acl},
+	})
+}
+
+func validatePrincipalType(v interface{}, k string) (we []string, errors []error) {
+	value := v.(string)
+	allowedWords := []string{"USER", "TEAM", "ORG"}
+	for _, word := range allowedWords {
+		if value == word {
+			return
+		}
+	}
+	errors = append(errors, fmt.Errorf("%s not allowed; must be one of: %s", value, strings.Join(allowedWords, ", ")))
+	return
+}
+
+func validatePermissionAction(v interface{}, k string) (we []string, errors []error) {
+	value := v.(string)
+	allowedWords := []string{"READ", "WRITE"}
+	for _, word := range allowedWords {
+		if value == word {
+			return
+		}
+	}
+	errors = append(errors, fmt.Errorf("%s not allowed; must be one of: %s", value, strings.Join(allowedWords, ", ")))
+	return
+}
@@ -0,0 +1,77 @@
+package signalform
+
+import (
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGetPermissions(t *testing.T) {
+	d := dashboardResource().TestResourceData()
+	d.Set("permissions", []interface{}{
+		map[string]interface{}{
+			"acl": []interface{}{
+				map[string]interface{}{"principal_id": "team1", "principal_type": "TEAM", "actions": []interface{}{"READ", "WRITE"}},
+				map[string]interface{}{"principal_id": "org", "principal_type": "ORG", "actions": []interface{}{"READ"}},
+			},
+		},
+	})
+
+	acl := getPermissions(d)["acl"].([]map[string]interface{})
+	assert.Equal(t, 2, len(acl))
+	assert.Equal(t, "team1", acl[0]["principalId"])
+	assert.Equal(t, "TEAM", acl[0]["principalType"])
+	assert.ElementsMatch(t, []interface{}{"READ", "WRITE"}, acl[0]["actions"])
+	assert.Equal(t, []interface{}{"READ"}, acl[1]["actions"])
+}
+
+func TestGetPermissionsNotSet(t *testing.T) {
+	d := dashboardResource().TestResourceData()
+	assert.Equal(t, map[string]interface{}{"acl": []map[string]interface{}{}}, getPermissions(d))
+}
+
+func TestPermissionsAPIToState(t *testing.T) {
+	d := dashboardGroupResource().TestResourceData()
+	assert.Nil(t, permissionsAPIToState(map[string]interface{}{"permissions": map[string]interface{}{"acl": []interface{}{}}}, d))
+	assert.Equal(t, 0, len(d.Get("permissions").([]interface{})))
+
+	assert.Nil(t, permissionsAPIToState(map[string]interface{}{
+		"permissions": map[string]interface{}{
+			"acl": []interface{}{
+				map[string]interface{}{"principalId": "team1", "principalType": "TEAM", "actions": []interface{}{"READ", "WRITE"}},
+			},
+		},
+	}, d))
+	assert.Equal(t, "team1", d.Get("permissions.0.acl.0.principal_id"))
+	assert.Equal(t, "TEAM", d.Get("permissions.0.acl.0.principal_type"))
+	assert.ElementsMatch(t, []interface{}{"READ", "WRITE"}, d.Get("permissions.0.acl.0.actions").(*schema.Set).List())
+
+	// Permissions removed in the UI empty the ACL of the block
+	assert.Nil(t, permissionsAPIToState(map[string]interface{}{}, d))
+	assert.Equal(t, 0, len(d.Get("permissions.0.acl").([]interface{})))
+}
+
+func TestValidatePrincipalType(t *testing.T) {
+	for _, value := range []string{"USER", "TEAM", "ORG"} {
+		_, errors := validatePrincipalType(value, "principal_type")
+		assert.Equal(t, 0, len(errors))
+	}
+	_, errors := validatePrincipalType("GROUP", "principal_type")
+	assert.Equal(t, 1, len(errors))
+}
+
+func TestValidatePermissionAction(t *testing.T) {
+	_, errors := validatePermissionAction("WRITE", "actions")
+	assert.Equal(t, 0, len(errors))
+	_, errors = validatePermissionAction("DELETE", "actions")
+	assert.Equal(t, 1, len(errors))
+}
+
+func TestGetAuthorizedWriters(t *testing.T) {
+	d := detectorResource().TestResourceData()
+	d.Set("authorized_writer_teams", []interface{}{"team1"})
+
+	writers := getAuthorizedWriters(d)
+	assert.Equal(t, []interface{}{}, writers["users"])
+	assert.Equal(t, []interface{}{"team1"}, writers["teams"])
+}
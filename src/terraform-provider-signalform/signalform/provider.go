@@ -11,14 +11,43 @@ import (
 	"os"
 	"os/user"
 	"runtime"
+	"sync"
 )
 
 var SystemConfigPath = "/etc/signalfx.conf"
 var HomeConfigSuffix = "/.signalfx.conf"
 var HomeConfigPath = ""
+var DefaultCredentialsPath = "~/.signalfx/credentials"
+
+const SESSION_API_URL = "https://api.signalfx.com/v2/session"
 
 type signalformConfig struct {
-	AuthToken string `json:"auth_token"`
+	AuthToken     string `json:"auth_token"`
+	RecordHTTPDir string `json:"record_http_dir"`
+	// Whether charts program_text must publish at least one stream
+	ValidateProgramPublish bool `json:"validate_program_publish"`
+	// Whether the integrations and teams referenced by detector notifications are checked at plan time
+	ValidateNotificationCredentials bool `json:"validate_notification_credentials"`
+
+	// Base URL of the SignalFx application of the organization, used in the computed url of resources. Set
+	// in a config file, a credentials profile or the provider configuration.
+	CustomAppURL string `json:"custom_app_url"`
+
+	// Value of the X-SF-Request-Tag header of every request, e.g. to attribute traffic to a pipeline
+	RequestTag string `json:"-"`
+	// Whether creating, updating and deleting resources is refused
+	ReadOnly bool `json:"-"`
+	// Whether reads of resources whose endpoint is not available for the organization are skipped
+	IgnoreUnsupported bool `json:"-"`
+	// max_delay and disable_sampling of the charts and detectors which do not set them
+	DefaultMaxDelay        int  `json:"-"`
+	DefaultDisableSampling bool `json:"-"`
+	// Dashboard group of the dashboards which do not set one
+	DefaultDashboardGroup string `json:"-"`
+
+	// Dashboard groups listing each dashboard, computed at most once per run (see getDashboardConfigGroupIdsCached)
+	dashboardGroupsLock     sync.Mutex
+	dashboardConfigGroupIds map[string][]string
 }
 
 func Provider() terraform.ResourceProvider {
@@ -30,65 +59,335 @@ func Provider() terraform.ResourceProvider {
 				DefaultFunc: schema.EnvDefaultFunc("SFX_AUTH_TOKEN", ""),
 				Description: "SignalFx auth token",
 			},
+			"email": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("SFX_EMAIL", ""),
+				Description: "Email of a SignalFx user. With password, the provider authenticates with a session token of this user instead of auth_token",
+			},
+			"password": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc("SFX_PASSWORD", ""),
+				Description: "Password of the user of email",
+			},
+			"organization_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("SFX_ORGANIZATION_ID", ""),
+				Description: "ID of the organization to open the session in, for users belonging to several organizations",
+			},
+			"record_http_dir": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("SFX_RECORD_HTTP_DIR", ""),
+				Description: "Directory where sanitized request/response pairs are written for debugging. Recording is disabled if not set",
+			},
+			"custom_app_url": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("SFX_CUSTOM_APP_URL", ""),
+				ValidateFunc: validateAppUrl,
+				Description:  "Base URL of the SignalFx application of the organization (e.g. https://app.eu0.signalfx.com), used in the url of resources instead of https://app.signalfx.com",
+			},
+			"profile": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("SFX_PROFILE", ""),
+				Description: "Profile of credentials_file to read the auth token and custom_app_url from",
+			},
+			"credentials_file": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("SFX_CREDENTIALS_FILE", DefaultCredentialsPath),
+				Description: "(~/.signalfx/credentials by default) JSON file of credentials profiles, read when profile is set",
+			},
+			"default_max_delay": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validateMaxDelayValue,
+				Description:  "max_delay of the charts and detectors which do not set it, as a number of seconds (e.g. 300, not \"5m\")",
+			},
+			"default_disable_sampling": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "(false by default) disable_sampling of the charts and detectors which do not set it",
+			},
+			"default_dashboard_group": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "ID of the dashboard group of the dashboards which do not set dashboard_group",
+			},
+			"max_concurrent_requests": &schema.Schema{
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     0,
+				Description: "(unlimited by default) Maximum number of requests sent to SignalFx at the same time",
+			},
+			"requests_per_second": &schema.Schema{
+				Type:        schema.TypeFloat,
+				Optional:    true,
+				Default:     0.0,
+				Description: "(unlimited by default) Maximum number of requests sent to SignalFx per second",
+			},
+			"request_tag": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("SFX_REQUEST_TAG", ""),
+				Description: "Value of the X-SF-Request-Tag header sent with every request, e.g. the name of the pipeline running Terraform",
+			},
+			"verify_token": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "(false by default) Whether to check that the auth token is valid when the provider is configured",
+			},
+			"expected_org_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("SFX_EXPECTED_ORG_ID", ""),
+				Description: "ID of the organization the auth token must belong to. Implies verify_token",
+			},
+			"read_only": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "(false by default) Refuse to create, update or delete resources, so that plans can run safely with production credentials",
+			},
+			"ignore_unsupported": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "(false by default) Skip reading resources whose API endpoint is not available for the organization, instead of failing",
+			},
+			"validate_notification_credentials": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "(false by default) Whether to check at plan time that the PagerDuty and Slack integrations referenced by detector notifications exist, have the right type and are enabled, and that the referenced teams exist",
+			},
+			"validate_program_publish": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "(true by default) Whether to check at plan time that the program_text of charts publishes at least one stream",
+			},
 		},
-		ResourcesMap: map[string]*schema.Resource{
-			"signalform_detector":           detectorResource(),
-			"signalform_time_chart":         timeChartResource(),
-			"signalform_heatmap_chart":      heatmapChartResource(),
-			"signalform_single_value_chart": singleValueChartResource(),
-			"signalform_list_chart":         listChartResource(),
-			"signalform_text_chart":         textChartResource(),
-			"signalform_dashboard":          dashboardResource(),
-			"signalform_dashboard_group":    dashboardGroupResource(),
-			"signalform_integration":        integrationResource(),
+		ResourcesMap: guardReadOnly(map[string]*schema.Resource{
+			"signalform_detector":              detectorResource(),
+			"signalform_heartbeat_detector":    heartbeatDetectorResource(),
+			"signalform_time_chart":            timeChartResource(),
+			"signalform_heatmap_chart":         heatmapChartResource(),
+			"signalform_table_chart":           tableChartResource(),
+			"signalform_single_value_chart":    singleValueChartResource(),
+			"signalform_list_chart":            listChartResource(),
+			"signalform_text_chart":            textChartResource(),
+			"signalform_log_view_chart":        logViewChartResource(),
+			"signalform_log_timeline_chart":    logTimelineChartResource(),
+			"signalform_dashboard":             dashboardResource(),
+			"signalform_dashboard_group":       dashboardGroupResource(),
+			"signalform_dashboard_mirror":      dashboardMirrorResource(),
+			"signalform_dashboard_chart":       dashboardChartResource(),
+			"signalform_integration":           integrationResource(),
+			"signalform_pagerduty_integration": pagerDutyIntegrationResource(),
+			"signalform_slack_integration":     slackIntegrationResource(),
+		}),
+		DataSourcesMap: map[string]*schema.Resource{
+			"signalform_notification_routing": notificationRoutingDataSource(),
+			"signalform_dashboard_group":      dashboardGroupDataSource(),
+			"signalform_dashboard":            dashboardDataSource(),
+			"signalform_dashboards":           dashboardsDataSource(),
+			"signalform_chart":                chartDataSource(),
+			"signalform_aws_services":         awsServicesDataSource(),
+			"signalform_integration":          integrationDataSource(),
+			"signalform_organization":         organizationDataSource(),
+			"signalform_metrics":              metricsDataSource(),
+			"signalform_notification":         notificationDataSource(),
+			"signalform_detector":             detectorDataSource(),
 		},
 		ConfigureFunc: signalformConfigure,
 	}
 }
 
+/*
+  Makes the create, update and delete functions of the resources fail when the provider is read only
+*/
+func guardReadOnly(resources map[string]*schema.Resource) map[string]*schema.Resource {
+	guard := func(action string, name string, f func(*schema.ResourceData, interface{}) error) func(*schema.ResourceData, interface{}) error {
+		if f == nil {
+			return nil
+		}
+		return func(d *schema.ResourceData, meta interface{}) error {
+			if config, ok := meta.(*signalformConfig); ok && config.ReadOnly {
+				return fmt.Errorf("Cannot %s %s %s: the provider is read only", action, name, d.Id())
+			}
+			return f(d, meta)
+		}
+	}
+	for name, resource := range resources {
+		resource.Create = guard("create", name, resource.Create)
+		resource.Update = guard("update", name, resource.Update)
+		resource.Delete = guard("delete", name, resource.Delete)
+	}
+	return resources
+}
+
 func signalformConfigure(data *schema.ResourceData) (interface{}, error) {
 	config := signalformConfig{}
 
-	// /etc/signalfx.conf has lowest priority
-	if _, err := os.Stat(SystemConfigPath); err == nil {
-		err = readConfigFile(SystemConfigPath, &config)
-		if err != nil {
+	err := readConfigFiles(&config)
+	if err != nil {
+		return nil, err
+	}
+
+	if profile, ok := data.GetOk("profile"); ok {
+		if err := readCredentialsProfile(data.Get("credentials_file").(string), profile.(string), &config); err != nil {
 			return nil, err
 		}
 	}
 
-	// $HOME/.signalfx.conf second
-	// this additional variable is used for mocking purposes in tests
-	if HomeConfigPath == "" {
-		usr, err := user.Current()
-		HomeConfigPath = usr.HomeDir + HomeConfigSuffix
+	// provider is the top priority
+	if token, ok := data.GetOk("auth_token"); ok {
+		config.AuthToken = token.(string)
+	}
+
+	if dir, ok := data.GetOk("record_http_dir"); ok {
+		config.RecordHTTPDir = dir.(string)
+	}
+	if config.RecordHTTPDir != "" {
+		if err := os.MkdirAll(config.RecordHTTPDir, 0700); err != nil {
+			return nil, fmt.Errorf("Failed to create record_http_dir %s: %s", config.RecordHTTPDir, err.Error())
+		}
+	}
+	RecordHTTPDir = config.RecordHTTPDir
+
+	email := data.Get("email").(string)
+	password := data.Get("password").(string)
+	if (email == "") != (password == "") {
+		return nil, fmt.Errorf("email and password must be set together")
+	}
+	if email != "" {
+		token, err := createSessionToken(email, password, data.Get("organization_id").(string))
 		if err != nil {
-			return nil, fmt.Errorf("Failed to get user environment %s", err.Error())
+			return nil, err
 		}
+		config.AuthToken = token
 	}
-	if _, err := os.Stat(HomeConfigPath); err == nil {
-		err = readConfigFile(HomeConfigPath, &config)
+	config.ValidateProgramPublish = data.Get("validate_program_publish").(bool)
+	config.ValidateNotificationCredentials = data.Get("validate_notification_credentials").(bool)
+	config.IgnoreUnsupported = data.Get("ignore_unsupported").(bool)
+	config.ReadOnly = data.Get("read_only").(bool)
+	config.RequestTag = data.Get("request_tag").(string)
+	config.DefaultMaxDelay = data.Get("default_max_delay").(int)
+	config.DefaultDisableSampling = data.Get("default_disable_sampling").(bool)
+	config.DefaultDashboardGroup = data.Get("default_dashboard_group").(string)
+	maxConcurrentRequests := data.Get("max_concurrent_requests").(int)
+	requestsPerSecond := data.Get("requests_per_second").(float64)
+	if maxConcurrentRequests < 0 || requestsPerSecond < 0 {
+		return nil, fmt.Errorf("max_concurrent_requests and requests_per_second must be >= 0")
+	}
+	setRequestLimits(maxConcurrentRequests, requestsPerSecond)
+	if appUrl, ok := data.GetOk("custom_app_url"); ok {
+		config.CustomAppURL = appUrl.(string)
+	}
+	if _, errors := validateAppUrl(config.CustomAppURL, "custom_app_url"); len(errors) > 0 {
+		return nil, errors[0]
+	}
+
+	if config.AuthToken == "" {
+		return &config, fmt.Errorf("auth_token: required field is not set")
+	}
+
+	expectedOrgId := data.Get("expected_org_id").(string)
+	if data.Get("verify_token").(bool) || expectedOrgId != "" {
+		organization, err := getSignalFxObject(ORGANIZATION_API_URL, &config)
 		if err != nil {
+			return nil, fmt.Errorf("Failed to verify the auth token: %s", err.Error())
+		}
+		if err := checkOrganization(organization, expectedOrgId); err != nil {
 			return nil, err
 		}
 	}
 
-	// Use netrc next
-	err := readNetrcFile(&config)
+	return &config, nil
+}
+
+/*
+  Opens a session of a user, whose token can do the operations restricted to users (e.g. administering teams)
+*/
+func createSessionToken(email string, password string, organizationId string) (string, error) {
+	session := map[string]string{
+		"email":    email,
+		"password": password,
+	}
+	if organizationId != "" {
+		session["organizationId"] = organizationId
+	}
+	payload, err := json.Marshal(session)
 	if err != nil {
-		return nil, err
+		return "", fmt.Errorf("Failed creating json payload: %s", err.Error())
+	}
+	status_code, resp_body, err := sendRequest("POST", SESSION_API_URL, &signalformConfig{}, payload)
+	if err != nil {
+		return "", err
 	}
+	if status_code != 200 {
+		return "", fmt.Errorf("Failed to open a session for %s: SignalFx returned status %d", email, status_code)
+	}
+	response := struct {
+		AccessToken string `json:"accessToken"`
+	}{}
+	if err := json.Unmarshal(resp_body, &response); err != nil {
+		return "", fmt.Errorf("Failed unmarshaling: %s", err.Error())
+	}
+	if response.AccessToken == "" {
+		return "", fmt.Errorf("Failed to open a session for %s: no access token in the response", email)
+	}
+	return response.AccessToken, nil
+}
 
-	// provider is the top priority
-	if token, ok := data.GetOk("auth_token"); ok {
-		config.AuthToken = token.(string)
+/*
+  Fails when the organization of the token is not the expected one, if any
+*/
+func checkOrganization(organization map[string]interface{}, expectedOrgId string) error {
+	if expectedOrgId == "" || organization["id"] == expectedOrgId {
+		return nil
 	}
+	return fmt.Errorf("The auth token belongs to the organization %v (%v), not to the expected organization %s", organization["organizationName"], organization["id"], expectedOrgId)
+}
 
-	if config.AuthToken == "" {
-		return &config, fmt.Errorf("auth_token: required field is not set")
+/*
+  Reads the configuration from the files outside of terraform, from the lowest to the highest priority
+*/
+func readConfigFiles(config *signalformConfig) error {
+	// /etc/signalfx.conf has lowest priority
+	if _, err := os.Stat(SystemConfigPath); err == nil {
+		err = readConfigFile(SystemConfigPath, config)
+		if err != nil {
+			return err
+		}
 	}
 
-	return &config, nil
+	// $HOME/.signalfx.conf second
+	// this additional variable is used for mocking purposes in tests
+	if HomeConfigPath == "" {
+		usr, err := user.Current()
+		if err != nil {
+			return fmt.Errorf("Failed to get user environment %s", err.Error())
+		}
+		HomeConfigPath = usr.HomeDir + HomeConfigSuffix
+	}
+	if _, err := os.Stat(HomeConfigPath); err == nil {
+		err = readConfigFile(HomeConfigPath, config)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Use netrc next
+	return readNetrcFile(config)
 }
 
 func readConfigFile(configPath string, config *signalformConfig) error {
@@ -103,6 +402,33 @@ func readConfigFile(configPath string, config *signalformConfig) error {
 	return nil
 }
 
+/*
+  Reads a profile of a credentials file, a JSON object of profiles each having the fields of the config files
+  (e.g. {"prod": {"auth_token": "XXX"}, "eu": {"auth_token": "YYY", "custom_app_url": "https://app.eu0.signalfx.com"}})
+*/
+func readCredentialsProfile(credentialsPath string, profile string, config *signalformConfig) error {
+	path, err := homedir.Expand(credentialsPath)
+	if err != nil {
+		return err
+	}
+	credentialsFile, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("Failed to open credentials file. %s", err.Error())
+	}
+	profiles := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(credentialsFile, &profiles); err != nil {
+		return fmt.Errorf("Failed to parse credentials file %s. %s", path, err.Error())
+	}
+	values, ok := profiles[profile]
+	if !ok {
+		return fmt.Errorf("No profile %s in credentials file %s", profile, path)
+	}
+	if err := json.Unmarshal(values, config); err != nil {
+		return fmt.Errorf("Failed to parse profile %s of credentials file %s. %s", profile, path, err.Error())
+	}
+	return nil
+}
+
 func readNetrcFile(config *signalformConfig) error {
 	// Inspired by https://github.com/hashicorp/terraform/blob/master/vendor/github.com/hashicorp/go-getter/netrc.go
 	// Get the netrc file path
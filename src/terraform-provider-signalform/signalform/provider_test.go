@@ -308,3 +308,109 @@ func TestReadConfigFileSuccess(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, "XXX", config.AuthToken)
 }
+
+func TestSignalformConfigureFromProfile(t *testing.T) {
+	defer resetGlobals()
+	SystemConfigPath = "filedoesnotexist"
+	tmpfileHome, err := createTempConfigFile(`{"auth_token":"WWW"}`, "signalform.conf")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.Remove(tmpfileHome.Name())
+	HomeConfigPath = tmpfileHome.Name()
+	tmpfileCredentials, err := createTempConfigFile(`{"us":{"auth_token":"XXX"},"eu":{"auth_token":"YYY","custom_app_url":"https://app.eu0.signalfx.com"}}`, "credentials")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.Remove(tmpfileCredentials.Name())
+	raw := map[string]interface{}{
+		"profile":          "eu",
+		"credentials_file": tmpfileCredentials.Name(),
+	}
+	rawConfig, err := config.NewRawConfig(raw)
+	if err != nil {
+		t.Fatalf("Error creating mock config: %s", err.Error())
+	}
+
+	rp := Provider()
+	err = rp.Configure(terraform.NewResourceConfig(rawConfig))
+	meta := rp.(*schema.Provider).Meta()
+	if meta == nil {
+		t.Fatalf("Expected metadata, got nil. err: %s", err.Error())
+	}
+	configuration := meta.(*signalformConfig)
+	assert.Equal(t, "YYY", configuration.AuthToken)
+	assert.Equal(t, "https://app.eu0.signalfx.com", configuration.CustomAppURL)
+}
+
+func TestReadCredentialsProfileNotFound(t *testing.T) {
+	config := signalformConfig{}
+	tmpfile, err := createTempConfigFile(`{"us":{"auth_token":"XXX"}}`, "credentials")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.Remove(tmpfile.Name())
+
+	err = readCredentialsProfile(tmpfile.Name(), "eu", &config)
+	assert.Contains(t, err.Error(), "No profile eu in credentials file")
+
+	err = readCredentialsProfile("filedoesnotexist", "us", &config)
+	assert.Contains(t, err.Error(), "Failed to open credentials file")
+}
+
+func TestProviderConfigureEmailWithoutPassword(t *testing.T) {
+	defer resetGlobals()
+	SystemConfigPath = "filedoesnotexist"
+	HomeConfigPath = "filedoesnotexist"
+	raw := map[string]interface{}{
+		"auth_token": "XXX",
+		"email":      "user@example.com",
+	}
+	rawConfig, err := config.NewRawConfig(raw)
+	if err != nil {
+		t.Fatalf("Error creating mock config: %s", err.Error())
+	}
+
+	rp := Provider()
+	err = rp.Configure(terraform.NewResourceConfig(rawConfig))
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "email and password must be set together")
+}
+
+func TestCheckOrganization(t *testing.T) {
+	organization := map[string]interface{}{"id": "O1", "organizationName": "Production"}
+	assert.Nil(t, checkOrganization(organization, ""))
+	assert.Nil(t, checkOrganization(organization, "O1"))
+
+	err := checkOrganization(organization, "O2")
+	assert.Contains(t, err.Error(), "The auth token belongs to the organization Production (O1), not to the expected organization O2")
+}
+
+func TestGuardReadOnly(t *testing.T) {
+	deleted := false
+	resources := guardReadOnly(map[string]*schema.Resource{
+		"signalform_test": &schema.Resource{
+			Create: func(d *schema.ResourceData, meta interface{}) error { return nil },
+			Read:   func(d *schema.ResourceData, meta interface{}) error { return nil },
+			Delete: func(d *schema.ResourceData, meta interface{}) error {
+				deleted = true
+				return nil
+			},
+		},
+	})
+	resource := resources["signalform_test"]
+	d := resource.TestResourceData()
+	d.SetId("abc")
+	assert.Nil(t, resource.Update)
+	assert.Nil(t, resource.Read(d, nil))
+
+	assert.Nil(t, resource.Delete(d, nil))
+	assert.True(t, deleted)
+
+	meta := &signalformConfig{ReadOnly: true}
+	deleted = false
+	err := resource.Delete(d, meta)
+	assert.Contains(t, err.Error(), "Cannot delete signalform_test abc: the provider is read only")
+	assert.False(t, deleted)
+	assert.Nil(t, resource.Read(d, meta))
+}
@@ -0,0 +1,114 @@
+package signalform
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Directory where HTTP exchanges are recorded. Empty means recording is disabled.
+// Set by the provider configuration; kept as a variable for mocking purposes in tests.
+var RecordHTTPDir = ""
+
+const REDACTED = "<redacted>"
+
+// Keys whose values are never written to a recording, at any depth of the payload
+var sensitiveRecordKeys = map[string]bool{
+	"accessToken": true,
+	"apiKey":      true,
+	"webhookUrl":  true,
+	"secret":      true,
+	"password":    true,
+	"token":       true,
+}
+
+var recordLock sync.Mutex
+var recordSequence = 0
+var recordRunId = time.Now().Unix()
+
+type recordedExchange struct {
+	Method     string      `json:"method"`
+	Url        string      `json:"url"`
+	Request    interface{} `json:"request,omitempty"`
+	StatusCode int         `json:"status_code"`
+	Response   interface{} `json:"response,omitempty"`
+	Error      string      `json:"error,omitempty"`
+}
+
+/*
+  Writes a sanitized request/response pair to RecordHTTPDir, one file per exchange.
+  Files are named <run>-<sequence>-<method>.json so that a run can be replayed in order.
+  The auth token is never recorded since it only travels in the request headers.
+*/
+func recordExchange(method string, url string, payload []byte, statusCode int, body []byte, reqErr error) {
+	if RecordHTTPDir == "" {
+		return
+	}
+
+	exchange := recordedExchange{
+		Method:     method,
+		Url:        url,
+		Request:    sanitizeRecordedBody(payload),
+		StatusCode: statusCode,
+		Response:   sanitizeRecordedBody(body),
+	}
+	if reqErr != nil {
+		exchange.Error = reqErr.Error()
+	}
+
+	// Not escaping HTML keeps <redacted> and URLs readable in the files
+	var content bytes.Buffer
+	encoder := json.NewEncoder(&content)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(exchange); err != nil {
+		return
+	}
+
+	recordLock.Lock()
+	recordSequence++
+	name := fmt.Sprintf("%d-%04d-%s.json", recordRunId, recordSequence, method)
+	recordLock.Unlock()
+
+	// Recording is a debugging aid, a failure to write must never fail the actual request
+	ioutil.WriteFile(filepath.Join(RecordHTTPDir, name), content.Bytes(), 0600)
+}
+
+/*
+  Decodes a JSON body and redacts sensitive values. Non JSON bodies are kept as plain strings.
+*/
+func sanitizeRecordedBody(body []byte) interface{} {
+	if len(body) == 0 {
+		return nil
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return string(body)
+	}
+	return redactSensitiveValues(decoded)
+}
+
+func redactSensitiveValues(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for key, elem := range value {
+			if sensitiveRecordKeys[key] {
+				value[key] = REDACTED
+			} else {
+				value[key] = redactSensitiveValues(elem)
+			}
+		}
+		return value
+	case []interface{}:
+		for i, elem := range value {
+			value[i] = redactSensitiveValues(elem)
+		}
+		return value
+	default:
+		return value
+	}
+}
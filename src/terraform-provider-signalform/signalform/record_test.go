@@ -0,0 +1,66 @@
+package signalform
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactSensitiveValues(t *testing.T) {
+	var payload interface{}
+	json.Unmarshal([]byte(`{"name":"PD","apiKey":"1234","rules":[{"notifications":[{"type":"Webhook","secret":"s3cr3t"}]}]}`), &payload)
+
+	redacted := redactSensitiveValues(payload).(map[string]interface{})
+	assert.Equal(t, "PD", redacted["name"])
+	assert.Equal(t, REDACTED, redacted["apiKey"])
+	notification := redacted["rules"].([]interface{})[0].(map[string]interface{})["notifications"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, REDACTED, notification["secret"])
+	assert.Equal(t, "Webhook", notification["type"])
+}
+
+func TestRedactSessionToken(t *testing.T) {
+	var payload interface{}
+	json.Unmarshal([]byte(`{"accessToken":"1234","userId":"U1"}`), &payload)
+
+	redacted := redactSensitiveValues(payload).(map[string]interface{})
+	assert.Equal(t, REDACTED, redacted["accessToken"])
+	assert.Equal(t, "U1", redacted["userId"])
+}
+
+func TestSanitizeRecordedBodyNotJson(t *testing.T) {
+	assert.Equal(t, "page not found", sanitizeRecordedBody([]byte("page not found")))
+	assert.Nil(t, sanitizeRecordedBody(nil))
+}
+
+func TestSendRequestRecording(t *testing.T) {
+	dir, err := ioutil.TempDir("", "signalform-record")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.RemoveAll(dir)
+	RecordHTTPDir = dir
+	defer func() { RecordHTTPDir = "" }()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+		fmt.Fprintln(w, `{"id":"abc","webhookUrl":"https://hooks.slack.com/xyz"}`)
+	}))
+	defer server.Close()
+
+	sendRequest("POST", server.URL, &signalformConfig{AuthToken: "token"}, []byte(`{"name":"Slack"}`))
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*-POST.json"))
+	assert.Equal(t, 1, len(files))
+	content, _ := ioutil.ReadFile(files[0])
+	assert.Contains(t, string(content), `"status_code": 200`)
+	assert.Contains(t, string(content), REDACTED)
+	assert.NotContains(t, string(content), "hooks.slack.com")
+	assert.NotContains(t, string(content), "token")
+}
@@ -0,0 +1,150 @@
+package signalform
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+/*
+  Order in which backup kinds are restored, so that the objects an object references (e.g. the group
+  and the charts of a dashboard) are restored before it
+*/
+var RestoreOrder = []string{"dashboardgroup", "chart", "dashboard", "detector"}
+
+/*
+  Entry point of the "restore" command. It applies a directory written by the "backup" command: objects
+  which still exist are updated in place, the others are created again. Since created objects get new
+  IDs, references to them are rewritten, and the old to new ID mapping is written to <dir>/id-mapping.json.
+*/
+func Restore(args []string) int {
+	flags := flag.NewFlagSet("restore", flag.ContinueOnError)
+	dir := flags.String("dir", "signalform-backup", "Directory written by the backup command")
+	authToken := flags.String("auth-token", "", "SignalFx auth token. Looked up like the provider does if not set")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	config, err := getCommandConfig(*authToken)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 1
+	}
+
+	idMapping := make(map[string]string)
+	restored := 0
+	for _, kind := range RestoreOrder {
+		paths, err := filepath.Glob(filepath.Join(*dir, kind, "*.json"))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return 1
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			if err := restoreObjectFromFile(kind, path, config, idMapping); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed restoring %s: %s\n", path, err.Error())
+				return 1
+			}
+			restored++
+		}
+	}
+
+	mapping, _ := json.MarshalIndent(idMapping, "", "  ")
+	mappingPath := filepath.Join(*dir, "id-mapping.json")
+	if err := ioutil.WriteFile(mappingPath, mapping, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write %s: %s\n", mappingPath, err.Error())
+		return 1
+	}
+	fmt.Printf("Restored %d objects from %s, %d of them with a new ID (see %s)\n", restored, *dir, len(idMapping), mappingPath)
+	return 0
+}
+
+func restoreObjectFromFile(kind string, path string, config *signalformConfig, idMapping map[string]string) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	object := map[string]interface{}{}
+	if err := json.Unmarshal(content, &object); err != nil {
+		return err
+	}
+	oldId := strings.TrimSuffix(filepath.Base(path), ".json")
+	payload, err := json.Marshal(getRestorePayload(kind, object, idMapping))
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/%s", BackupKinds[kind], oldId)
+	status_code, resp_body, err := sendRequest("GET", url, config, nil)
+	if err != nil {
+		return err
+	}
+	if status_code == 200 {
+		status_code, resp_body, err = sendRequest("PUT", url, config, payload)
+		if err != nil {
+			return err
+		}
+		if status_code != 200 {
+			return fmt.Errorf("SignalFx returned status %d: \n%s", status_code, resp_body)
+		}
+		return nil
+	}
+	if status_code != 404 {
+		return fmt.Errorf("SignalFx returned status %d: \n%s", status_code, resp_body)
+	}
+
+	status_code, resp_body, err = sendRequest("POST", BackupKinds[kind], config, payload)
+	if err != nil {
+		return err
+	}
+	if status_code != 200 {
+		return fmt.Errorf("SignalFx returned status %d: \n%s", status_code, resp_body)
+	}
+	created := map[string]interface{}{}
+	if err := json.Unmarshal(resp_body, &created); err != nil {
+		return err
+	}
+	if newId, ok := created["id"].(string); ok {
+		idMapping[oldId] = newId
+	}
+	return nil
+}
+
+/*
+  Strips the read-only fields of a backed up object and rewrites the IDs of the objects it references
+  which were created again during the restore
+*/
+func getRestorePayload(kind string, object map[string]interface{}, idMapping map[string]string) map[string]interface{} {
+	for _, field := range readOnlyFields {
+		delete(object, field)
+	}
+	mapId := func(id interface{}) interface{} {
+		if newId, ok := idMapping[fmt.Sprintf("%s", id)]; ok {
+			return newId
+		}
+		return id
+	}
+
+	switch kind {
+	case "dashboardgroup":
+		// Dashboards are attached to groups when they are restored
+		object["dashboards"] = make([]string, 0)
+	case "dashboard":
+		if groupId, ok := object["groupId"]; ok {
+			object["groupId"] = mapId(groupId)
+		}
+		if charts, ok := object["charts"].([]interface{}); ok {
+			for _, chart := range charts {
+				if chart, ok := chart.(map[string]interface{}); ok {
+					chart["chartId"] = mapId(chart["chartId"])
+				}
+			}
+		}
+	}
+	return object
+}
@@ -0,0 +1,44 @@
+package signalform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetRestorePayloadDashboard(t *testing.T) {
+	dashboard := map[string]interface{}{
+		"id":          "OldDash",
+		"lastUpdated": float64(1518000000000),
+		"name":        "My dashboard",
+		"groupId":     "OldGroup",
+		"charts": []interface{}{
+			map[string]interface{}{"chartId": "OldChart", "row": float64(0)},
+			map[string]interface{}{"chartId": "KeptChart", "row": float64(1)},
+		},
+	}
+	idMapping := map[string]string{"OldGroup": "NewGroup", "OldChart": "NewChart"}
+
+	payload := getRestorePayload("dashboard", dashboard, idMapping)
+	assert.Equal(t, map[string]interface{}{
+		"name":    "My dashboard",
+		"groupId": "NewGroup",
+		"charts": []interface{}{
+			map[string]interface{}{"chartId": "NewChart", "row": float64(0)},
+			map[string]interface{}{"chartId": "KeptChart", "row": float64(1)},
+		},
+	}, payload)
+}
+
+func TestGetRestorePayloadDashboardGroup(t *testing.T) {
+	group := map[string]interface{}{
+		"id":         "OldGroup",
+		"name":       "My group",
+		"dashboards": []interface{}{"OldDash"},
+	}
+
+	payload := getRestorePayload("dashboardgroup", group, map[string]string{})
+	assert.Equal(t, "My group", payload["name"])
+	assert.Equal(t, make([]string, 0), payload["dashboards"])
+	assert.NotContains(t, payload, "id")
+}
@@ -3,8 +3,8 @@ package signalform
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/hashicorp/terraform/helper/customdiff"
 	"github.com/hashicorp/terraform/helper/schema"
-	"math"
 )
 
 func singleValueChartResource() *schema.Resource {
@@ -48,14 +48,21 @@ func singleValueChartResource() *schema.Resource {
 				Description: "Signalflow program text for the chart. More info at \"https://developers.signalfx.com/docs/signalflow-overview\"",
 			},
 			"unit_prefix": &schema.Schema{
-				Type:        schema.TypeString,
-				Optional:    true,
-				Description: "(Metric by default) Must be \"Metric\" or \"Binary\"",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "(Metric by default) Must be \"Metric\" or \"Binary\"",
+				ValidateFunc: validateUnitPrefix,
 			},
 			"color_by": &schema.Schema{
-				Type:        schema.TypeString,
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "(Metric by default) Must be \"Metric\", \"Dimension\", or \"Scale\". \"Scale\" maps to Color by Value in the UI",
+				ValidateFunc: validateColorBy,
+			},
+			"minimum_resolution": &schema.Schema{
+				Type:        schema.TypeInt,
 				Optional:    true,
-				Description: "(Metric by default) Must be \"Metric\", \"Dimension\", or \"Scale\". \"Scale\" maps to Color by Value in the UI",
+				Description: "The minimum resolution (in seconds) to use for computing the underlying program",
 			},
 			"max_delay": &schema.Schema{
 				Type:         schema.TypeInt,
@@ -63,6 +70,17 @@ func singleValueChartResource() *schema.Resource {
 				Description:  "How long (in seconds) to wait for late datapoints",
 				ValidateFunc: validateMaxDelayValue,
 			},
+			"disable_sampling": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "(default_disable_sampling of the provider by default) If false, samples a subset of the output MTS, which improves UI performance",
+			},
+			"timezone": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateTimezone,
+				Description:  "Timezone in which the chart is rendered (e.g. UTC, Europe/Paris), whatever the timezone of the viewer",
+			},
 			"refresh_interval": &schema.Schema{
 				Type:        schema.TypeInt,
 				Optional:    true,
@@ -88,48 +106,10 @@ func singleValueChartResource() *schema.Resource {
 			"secondary_visualization": &schema.Schema{
 				Type:         schema.TypeString,
 				Optional:     true,
-				Description:  "(false by default) What kind of secondary visualization to show (None, Radial, Linear, Sparkline)",
+				Description:  "What kind of secondary visualization to show (None, Radial, Linear, Sparkline). The SignalFx default is used if unset",
 				ValidateFunc: validateSecondaryVisualization,
 			},
-			"color_scale": &schema.Schema{
-				Type:        schema.TypeSet,
-				Optional:    true,
-				Description: "Single color range including both the color to display for that range and the borders of the range",
-				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						"gt": &schema.Schema{
-							Type:        schema.TypeFloat,
-							Optional:    true,
-							Default:     math.MaxFloat32,
-							Description: "Indicates the lower threshold non-inclusive value for this range",
-						},
-						"gte": &schema.Schema{
-							Type:        schema.TypeFloat,
-							Optional:    true,
-							Default:     math.MaxFloat32,
-							Description: "Indicates the lower threshold inclusive value for this range",
-						},
-						"lt": &schema.Schema{
-							Type:        schema.TypeFloat,
-							Optional:    true,
-							Default:     math.MaxFloat32,
-							Description: "Indicates the upper threshold non-inculsive value for this range",
-						},
-						"lte": &schema.Schema{
-							Type:        schema.TypeFloat,
-							Optional:    true,
-							Default:     math.MaxFloat32,
-							Description: "Indicates the upper threshold inclusive value for this range",
-						},
-						"color": &schema.Schema{
-							Type:         schema.TypeString,
-							Required:     true,
-							Description:  "The color to use. Must be either \"gray\", \"blue\", \"navy\", \"orange\", \"yellow\", \"magenta\", \"purple\", \"violet\", \"lilac\", \"green\", \"aquamarine\"",
-							ValidateFunc: validateHeatmapChartColor,
-						},
-					},
-				},
-			},
+			"color_scale": colorScaleSchema(),
 			"viz_options": &schema.Schema{
 				Type:        schema.TypeSet,
 				Optional:    true,
@@ -141,6 +121,11 @@ func singleValueChartResource() *schema.Resource {
 							Required:    true,
 							Description: "The label used in the publish statement that displays the plot (metric time series data) you want to customize",
 						},
+						"display_name": &schema.Schema{
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Name of the plot in the chart, instead of its label",
+						},
 						"color": &schema.Schema{
 							Type:         schema.TypeString,
 							Optional:     true,
@@ -172,20 +157,22 @@ func singleValueChartResource() *schema.Resource {
 		Read:   singlevaluechartRead,
 		Update: singlevaluechartUpdate,
 		Delete: singlevaluechartDelete,
+
+		CustomizeDiff: customdiff.All(validateProgramTextPublishes, validateColorByScale),
 	}
 }
 
 /*
   Use Resource object to construct json payload in order to create a single value chart
 */
-func getPayloadSingleValueChart(d *schema.ResourceData) ([]byte, error) {
+func getPayloadSingleValueChart(d *schema.ResourceData, config *signalformConfig) ([]byte, error) {
 	payload := map[string]interface{}{
 		"name":        d.Get("name").(string),
 		"description": d.Get("description").(string),
 		"programText": d.Get("program_text").(string),
 	}
 
-	viz := getSingleValueChartOptions(d)
+	viz := getSingleValueChartOptions(d, config)
 	if vizOptions := getPerSignalVizOptions(d); len(vizOptions) > 0 {
 		viz["publishLabelOptions"] = vizOptions
 	}
@@ -196,7 +183,7 @@ func getPayloadSingleValueChart(d *schema.ResourceData) ([]byte, error) {
 	return json.Marshal(payload)
 }
 
-func getSingleValueChartOptions(d *schema.ResourceData) map[string]interface{} {
+func getSingleValueChartOptions(d *schema.ResourceData, config *signalformConfig) map[string]interface{} {
 	viz := make(map[string]interface{})
 	viz["type"] = "SingleValue"
 	if val, ok := d.GetOk("unit_prefix"); ok {
@@ -214,9 +201,16 @@ func getSingleValueChartOptions(d *schema.ResourceData) map[string]interface{} {
 	}
 
 	programOptions := make(map[string]interface{})
-	if val, ok := d.GetOk("max_delay"); ok {
-		programOptions["maxDelay"] = val.(int) * 1000
-		viz["programOptions"] = programOptions
+	if val, ok := d.GetOk("minimum_resolution"); ok {
+		programOptions["minimumResolution"] = val.(int) * 1000
+	}
+	if maxDelay, ok := getMaxDelay(d.GetOkExists, config); ok {
+		programOptions["maxDelay"] = maxDelay * 1000
+	}
+	programOptions["disableSampling"] = getDisableSampling(d, config)
+	viz["programOptions"] = programOptions
+	if val, ok := d.GetOk("timezone"); ok {
+		viz["timezone"] = val.(string)
 	}
 
 	if refreshInterval, ok := d.GetOk("refresh_interval"); ok {
@@ -239,34 +233,34 @@ func getSingleValueChartOptions(d *schema.ResourceData) map[string]interface{} {
 
 func singlevaluechartCreate(d *schema.ResourceData, meta interface{}) error {
 	config := meta.(*signalformConfig)
-	payload, err := getPayloadSingleValueChart(d)
+	payload, err := getPayloadSingleValueChart(d, config)
 	if err != nil {
 		return fmt.Errorf("Failed creating json payload: %s", err.Error())
 	}
 
-	return resourceCreate(CHART_API_URL, config.AuthToken, payload, d)
+	return resourceCreate(CHART_API_URL, config, payload, d)
 }
 
 func singlevaluechartRead(d *schema.ResourceData, meta interface{}) error {
 	config := meta.(*signalformConfig)
 	url := fmt.Sprintf("%s/%s", CHART_API_URL, d.Id())
 
-	return resourceRead(url, config.AuthToken, d)
+	return resourceRead(url, config, d)
 }
 
 func singlevaluechartUpdate(d *schema.ResourceData, meta interface{}) error {
 	config := meta.(*signalformConfig)
-	payload, err := getPayloadSingleValueChart(d)
+	payload, err := getPayloadSingleValueChart(d, config)
 	if err != nil {
 		return fmt.Errorf("Failed creating json payload: %s", err.Error())
 	}
 	url := fmt.Sprintf("%s/%s", CHART_API_URL, d.Id())
 
-	return resourceUpdate(url, config.AuthToken, payload, d)
+	return resourceUpdate(url, config, payload, d)
 }
 
 func singlevaluechartDelete(d *schema.ResourceData, meta interface{}) error {
 	config := meta.(*signalformConfig)
 	url := fmt.Sprintf("%s/%s", CHART_API_URL, d.Id())
-	return resourceDelete(url, config.AuthToken, d)
+	return resourceDelete(url, config, d)
 }
@@ -0,0 +1,73 @@
+package signalform
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGetSingleValueChartOptionsColorBy(t *testing.T) {
+	d := singleValueChartResource().TestResourceData()
+	d.Set("color_by", "Dimension")
+	viz := getSingleValueChartOptions(d, &signalformConfig{})
+	assert.Equal(t, "Dimension", viz["colorBy"])
+	_, ok := viz["colorScale2"]
+	assert.False(t, ok)
+
+	d.Set("color_by", "Scale")
+	d.Set("color_scale", []interface{}{
+		map[string]interface{}{"gt": 90.0, "color": "magenta"},
+		map[string]interface{}{"lte": 90.0, "color": "green"},
+	})
+	viz = getSingleValueChartOptions(d, &signalformConfig{})
+	assert.Equal(t, "Scale", viz["colorBy"])
+	assert.Equal(t, 2, len(viz["colorScale2"].([]interface{})))
+}
+
+func TestGetSingleValueChartOptionsSecondaryVisualization(t *testing.T) {
+	d := singleValueChartResource().TestResourceData()
+	viz := getSingleValueChartOptions(d, &signalformConfig{})
+	_, ok := viz["secondaryVisualization"]
+	assert.False(t, ok)
+
+	d.Set("secondary_visualization", "Sparkline")
+	viz = getSingleValueChartOptions(d, &signalformConfig{})
+	assert.Equal(t, "Sparkline", viz["secondaryVisualization"])
+}
+
+func TestGetSingleValueChartOptionsProgramOptions(t *testing.T) {
+	d := singleValueChartResource().TestResourceData()
+	viz := getSingleValueChartOptions(d, &signalformConfig{})
+	assert.Equal(t, map[string]interface{}{"disableSampling": false}, viz["programOptions"])
+
+	d.Set("timezone", "Europe/Paris")
+	d.Set("disable_sampling", true)
+	viz = getSingleValueChartOptions(d, &signalformConfig{})
+	assert.Equal(t, map[string]interface{}{"disableSampling": true}, viz["programOptions"])
+	assert.Equal(t, "Europe/Paris", viz["timezone"])
+}
+
+func TestGetSingleValueChartOptionsResolution(t *testing.T) {
+	d := singleValueChartResource().TestResourceData()
+	d.Set("minimum_resolution", 60)
+	d.Set("max_delay", 30)
+	viz := getSingleValueChartOptions(d, &signalformConfig{})
+	assert.Equal(t, map[string]interface{}{"minimumResolution": 60000, "maxDelay": 30000, "disableSampling": false}, viz["programOptions"])
+}
+
+func TestGetSingleValueChartOptionsProviderDefaults(t *testing.T) {
+	config := &signalformConfig{DefaultMaxDelay: 300, DefaultDisableSampling: true}
+
+	d := singleValueChartResource().TestResourceData()
+	viz := getSingleValueChartOptions(d, config)
+	assert.Equal(t, map[string]interface{}{"maxDelay": 300000, "disableSampling": true}, viz["programOptions"])
+
+	d.Set("max_delay", 30)
+	viz = getSingleValueChartOptions(d, config)
+	assert.Equal(t, map[string]interface{}{"maxDelay": 30000, "disableSampling": true}, viz["programOptions"])
+
+	// Explicit zero values override the provider defaults
+	d.Set("max_delay", 0)
+	d.Set("disable_sampling", false)
+	viz = getSingleValueChartOptions(d, config)
+	assert.Equal(t, map[string]interface{}{"maxDelay": 0, "disableSampling": false}, viz["programOptions"])
+}
@@ -0,0 +1,9 @@
+package signalform
+
+import (
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func slackIntegrationResource() *schema.Resource {
+	return typedIntegrationResource("Slack", "Slack Incoming Webhook URL")
+}
@@ -0,0 +1,242 @@
+package signalform
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/hashicorp/terraform/helper/customdiff"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+/*
+  Table charts show the latest values of the published streams in a table, with a row for each value of
+  the group_by properties
+*/
+func tableChartResource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"synced": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Whether the resource in SignalForm and SignalFx are identical or not. Used internally for syncing.",
+			},
+			"last_updated": &schema.Schema{
+				Type:        schema.TypeFloat,
+				Computed:    true,
+				Description: "Latest timestamp the resource was updated",
+			},
+			"resource_url": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     CHART_URL,
+				Description: "API URL of the chart",
+			},
+			"url": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "URL of the chart",
+			},
+			"name": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Name of the chart",
+			},
+			"description": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Description of the chart (Optional)",
+			},
+			"program_text": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Signalflow program text for the chart. More info at \"https://developers.signalfx.com/docs/signalflow-overview\"",
+			},
+			"unit_prefix": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "(Metric by default) Must be \"Metric\" or \"Binary\"",
+				ValidateFunc: validateUnitPrefix,
+			},
+			"minimum_resolution": &schema.Schema{
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Description: "The minimum resolution (in seconds) to use for computing the underlying program",
+			},
+			"max_delay": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Description:  "How long (in seconds) to wait for late datapoints",
+				ValidateFunc: validateMaxDelayValue,
+			},
+			"timezone": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateTimezone,
+				Description:  "Timezone in which the chart is rendered (e.g. UTC, Europe/Paris), whatever the timezone of the viewer",
+			},
+			"disable_sampling": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "(default_disable_sampling of the provider by default) If false, samples a subset of the output MTS, which improves UI performance",
+			},
+			"group_by": &schema.Schema{
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Properties to group the rows of the table by (e.g. service, environment), in nesting order",
+			},
+			"hide_missing_values": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "(false by default) Whether to hide the rows without any value for some of the published streams",
+			},
+			"refresh_interval": &schema.Schema{
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Description: "How often (in seconds) to refresh the values of the table",
+			},
+			"max_precision": &schema.Schema{
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Description: "Maximum number of digits to display when rounding values up or down",
+			},
+			"viz_options": &schema.Schema{
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "Plot-level customization options, associated with a publish statement",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"label": &schema.Schema{
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The label used in the publish statement that displays the plot (metric time series data) you want to customize",
+						},
+						"display_name": &schema.Schema{
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Name of the plot in the chart, instead of its label",
+						},
+						"color": &schema.Schema{
+							Type:         schema.TypeString,
+							Optional:     true,
+							Description:  "Color to use",
+							ValidateFunc: validatePerSignalColor,
+						},
+						"value_unit": &schema.Schema{
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validateUnitTimeChart,
+							Description:  "A unit to attach to this plot. Units support automatic scaling (eg thousands of bytes will be displayed as kilobytes)",
+						},
+						"value_prefix": &schema.Schema{
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "An arbitrary prefix to display with the value of this plot",
+						},
+						"value_suffix": &schema.Schema{
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "An arbitrary suffix to display with the value of this plot",
+						},
+					},
+				},
+			},
+		},
+
+		Create: tablechartCreate,
+		Read:   tablechartRead,
+		Update: tablechartUpdate,
+		Delete: tablechartDelete,
+
+		CustomizeDiff: customdiff.All(validateProgramTextPublishes, validateGroupBy),
+	}
+}
+
+/*
+  Use Resource object to construct json payload in order to create a table chart
+*/
+func getPayloadTableChart(d *schema.ResourceData, config *signalformConfig) ([]byte, error) {
+	payload := map[string]interface{}{
+		"name":        d.Get("name").(string),
+		"description": d.Get("description").(string),
+		"programText": d.Get("program_text").(string),
+	}
+
+	viz := getTableChartOptions(d, config)
+	if vizOptions := getPerSignalVizOptions(d); len(vizOptions) > 0 {
+		viz["publishLabelOptions"] = vizOptions
+	}
+	payload["options"] = viz
+
+	return json.Marshal(payload)
+}
+
+func getTableChartOptions(d *schema.ResourceData, config *signalformConfig) map[string]interface{} {
+	viz := make(map[string]interface{})
+	viz["type"] = "TableChart"
+	if val, ok := d.GetOk("unit_prefix"); ok {
+		viz["unitPrefix"] = val.(string)
+	}
+
+	programOptions := make(map[string]interface{})
+	if val, ok := d.GetOk("minimum_resolution"); ok {
+		programOptions["minimumResolution"] = val.(int) * 1000
+	}
+	if maxDelay, ok := getMaxDelay(d.GetOkExists, config); ok {
+		programOptions["maxDelay"] = maxDelay * 1000
+	}
+	programOptions["disableSampling"] = getDisableSampling(d, config)
+	viz["programOptions"] = programOptions
+	if val, ok := d.GetOk("timezone"); ok {
+		viz["timezone"] = val.(string)
+	}
+
+	if groupBy, ok := d.GetOk("group_by"); ok {
+		viz["groupBy"] = groupBy.([]interface{})
+	}
+	viz["hideMissingValues"] = d.Get("hide_missing_values").(bool)
+	if refreshInterval, ok := d.GetOk("refresh_interval"); ok {
+		viz["refreshInterval"] = refreshInterval.(int) * 1000
+	}
+	if maxPrecision, ok := d.GetOk("max_precision"); ok {
+		viz["maximumPrecision"] = maxPrecision.(int)
+	}
+
+	return viz
+}
+
+func tablechartCreate(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*signalformConfig)
+	payload, err := getPayloadTableChart(d, config)
+	if err != nil {
+		return fmt.Errorf("Failed creating json payload: %s", err.Error())
+	}
+
+	return resourceCreate(CHART_API_URL, config, payload, d)
+}
+
+func tablechartRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*signalformConfig)
+	url := fmt.Sprintf("%s/%s", CHART_API_URL, d.Id())
+
+	return resourceRead(url, config, d)
+}
+
+func tablechartUpdate(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*signalformConfig)
+	payload, err := getPayloadTableChart(d, config)
+	if err != nil {
+		return fmt.Errorf("Failed creating json payload: %s", err.Error())
+	}
+	url := fmt.Sprintf("%s/%s", CHART_API_URL, d.Id())
+
+	return resourceUpdate(url, config, payload, d)
+}
+
+func tablechartDelete(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*signalformConfig)
+	url := fmt.Sprintf("%s/%s", CHART_API_URL, d.Id())
+
+	return resourceDelete(url, config, d)
+}
@@ -0,0 +1,29 @@
+package signalform
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGetPayloadTableChart(t *testing.T) {
+	d := tableChartResource().TestResourceData()
+	d.Set("name", "Latency per service")
+	d.Set("program_text", "data('api.latency').mean(by=['service', 'environment']).publish(label='latency')")
+	d.Set("group_by", []interface{}{"service", "environment"})
+	d.Set("refresh_interval", 60)
+	d.Set("viz_options", []interface{}{
+		map[string]interface{}{"label": "latency", "value_unit": "Millisecond"},
+	})
+
+	payload, err := getPayloadTableChart(d, &signalformConfig{})
+	assert.Nil(t, err)
+	var chart map[string]interface{}
+	assert.Nil(t, json.Unmarshal(payload, &chart))
+	options := chart["options"].(map[string]interface{})
+	assert.Equal(t, "TableChart", options["type"])
+	assert.Equal(t, []interface{}{"service", "environment"}, options["groupBy"])
+	assert.Equal(t, false, options["hideMissingValues"])
+	assert.Equal(t, 60000.0, options["refreshInterval"])
+	assert.Equal(t, "Millisecond", options["publishLabelOptions"].([]interface{})[0].(map[string]interface{})["valueUnit"])
+}
@@ -89,14 +89,14 @@ func textchartCreate(d *schema.ResourceData, meta interface{}) error {
 		return fmt.Errorf("Failed creating json payload: %s", err.Error())
 	}
 
-	return resourceCreate(CHART_API_URL, config.AuthToken, payload, d)
+	return resourceCreate(CHART_API_URL, config, payload, d)
 }
 
 func textchartRead(d *schema.ResourceData, meta interface{}) error {
 	config := meta.(*signalformConfig)
 	url := fmt.Sprintf("%s/%s", CHART_API_URL, d.Id())
 
-	return resourceRead(url, config.AuthToken, d)
+	return resourceRead(url, config, d)
 }
 
 func textchartUpdate(d *schema.ResourceData, meta interface{}) error {
@@ -107,11 +107,11 @@ func textchartUpdate(d *schema.ResourceData, meta interface{}) error {
 	}
 	url := fmt.Sprintf("%s/%s", CHART_API_URL, d.Id())
 
-	return resourceUpdate(url, config.AuthToken, payload, d)
+	return resourceUpdate(url, config, payload, d)
 }
 
 func textchartDelete(d *schema.ResourceData, meta interface{}) error {
 	config := meta.(*signalformConfig)
 	url := fmt.Sprintf("%s/%s", CHART_API_URL, d.Id())
-	return resourceDelete(url, config.AuthToken, d)
+	return resourceDelete(url, config, d)
 }
@@ -7,8 +7,10 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/hashicorp/terraform/helper/customdiff"
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
+	"terraform-provider-signalform/internal/sfxtime"
 )
 
 var PaletteColors = map[string]int{
@@ -131,9 +133,10 @@ func timeChartResource() *schema.Resource {
 				Description: "Signalflow program text for the chart. More info at \"https://developers.signalfx.com/docs/signalflow-overview\"",
 			},
 			"unit_prefix": &schema.Schema{
-				Type:        schema.TypeString,
-				Optional:    true,
-				Description: "(Metric by default) Must be \"Metric\" or \"Binary\"",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "(Metric by default) Must be \"Metric\" or \"Binary\"",
+				ValidateFunc: validateUnitPrefix,
 			},
 			"color_by": &schema.Schema{
 				Type:        schema.TypeString,
@@ -154,12 +157,18 @@ func timeChartResource() *schema.Resource {
 			"disable_sampling": &schema.Schema{
 				Type:        schema.TypeBool,
 				Optional:    true,
-				Description: "(false by default) If false, samples a subset of the output MTS, which improves UI performance",
+				Description: "(default_disable_sampling of the provider by default) If false, samples a subset of the output MTS, which improves UI performance",
+			},
+			"timezone": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateTimezone,
+				Description:  "Timezone in which the chart is rendered (e.g. UTC, Europe/Paris), whatever the timezone of the viewer",
 			},
 			"time_range": &schema.Schema{
 				Type:          schema.TypeString,
 				Optional:      true,
-				ValidateFunc:  validateSignalfxRelativeTime,
+				ValidateFunc:  sfxtime.Validate,
 				Description:   "From when to display data. SignalFx time syntax (e.g. -5m, -1h)",
 				ConflictsWith: []string{"start_time", "end_time"},
 			},
@@ -176,8 +185,10 @@ func timeChartResource() *schema.Resource {
 				ConflictsWith: []string{"time_range"},
 			},
 			"axis_right": &schema.Schema{
-				Type:     schema.TypeSet,
-				Optional: true,
+				Type:        schema.TypeSet,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "Options of the right Y-axis",
 				Elem: &schema.Resource{
 					SchemaVersion: 1,
 					MigrateState:  resourceAxisMigrateState,
@@ -222,8 +233,9 @@ func timeChartResource() *schema.Resource {
 							Description: "A label to attach to the low watermark line",
 						},
 						"watermarks": &schema.Schema{
-							Type:     schema.TypeSet,
-							Optional: true,
+							Type:       schema.TypeSet,
+							Optional:   true,
+							Deprecated: "watermarks are not sent to SignalFx, use high_watermark and low_watermark instead",
 							Elem: &schema.Resource{
 								Schema: map[string]*schema.Schema{
 									"value": &schema.Schema{
@@ -243,8 +255,10 @@ func timeChartResource() *schema.Resource {
 				},
 			},
 			"axis_left": &schema.Schema{
-				Type:     schema.TypeSet,
-				Optional: true,
+				Type:        schema.TypeSet,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "Options of the left Y-axis",
 				Elem: &schema.Resource{
 					SchemaVersion: 1,
 					MigrateState:  resourceAxisMigrateState,
@@ -289,8 +303,9 @@ func timeChartResource() *schema.Resource {
 							Description: "A label to attach to the low watermark line",
 						},
 						"watermarks": &schema.Schema{
-							Type:     schema.TypeSet,
-							Optional: true,
+							Type:       schema.TypeSet,
+							Optional:   true,
+							Deprecated: "watermarks are not sent to SignalFx, use high_watermark and low_watermark instead",
 							Elem: &schema.Resource{
 								Schema: map[string]*schema.Schema{
 									"value": &schema.Schema{
@@ -325,10 +340,37 @@ func timeChartResource() *schema.Resource {
 				Description: "Dimension to show in the on-chart legend. On-chart legend is off unless a dimension is specified. Allowed: 'metric', 'plot_label' and any dimension.",
 			},
 			"legend_fields_to_hide": &schema.Schema{
+				Type:          schema.TypeSet,
+				Optional:      true,
+				Elem:          &schema.Schema{Type: schema.TypeString},
+				ConflictsWith: []string{"legend_options_fields"},
+				Description:   "List of properties that shouldn't be displayed in the chart legend (i.e. dimension names)",
+			},
+			"legend_options_fields": legendOptionsFieldsSchema(),
+			"event_options": &schema.Schema{
 				Type:        schema.TypeSet,
 				Optional:    true,
-				Elem:        &schema.Schema{Type: schema.TypeString},
-				Description: "List of properties that shouldn't be displayed in the chart legend (i.e. dimension names)",
+				Description: "Display options of the events published by program_text (e.g. events(eventType='deploy').publish('deploys'))",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"label": &schema.Schema{
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The label used in the publish statement of the events",
+						},
+						"display_name": &schema.Schema{
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Name of the events in the chart, instead of their label",
+						},
+						"color": &schema.Schema{
+							Type:         schema.TypeString,
+							Optional:     true,
+							Description:  "Color to use",
+							ValidateFunc: validatePerSignalColor,
+						},
+					},
+				},
 			},
 			"show_event_lines": &schema.Schema{
 				Type:        schema.TypeBool,
@@ -356,6 +398,7 @@ func timeChartResource() *schema.Resource {
 			"histogram_options": &schema.Schema{
 				Type:        schema.TypeSet,
 				Optional:    true,
+				MaxItems:    1,
 				Description: "Options specific to Histogram charts",
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
@@ -379,6 +422,11 @@ func timeChartResource() *schema.Resource {
 							Required:    true,
 							Description: "The label used in the publish statement that displays the plot (metric time series data) you want to customize",
 						},
+						"display_name": &schema.Schema{
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Name of the plot in the chart, instead of its label",
+						},
 						"color": &schema.Schema{
 							Type:         schema.TypeString,
 							Optional:     true,
@@ -422,20 +470,122 @@ func timeChartResource() *schema.Resource {
 		Read:   timechartRead,
 		Update: timechartUpdate,
 		Delete: timechartDelete,
+
+		CustomizeDiff: customdiff.All(validateProgramTextPublishes, validateTimeChartAxes, validateTimeChartEventOptions, validateTimeChartStacked),
+	}
+}
+
+/*
+  Checks the bounds of the axes and the plot options at plan time, since SignalFx accepts inverted bounds
+  and renders an empty chart
+*/
+func validateTimeChartAxes(diff *schema.ResourceDiff, meta interface{}) error {
+	for _, name := range []string{"axis_left", "axis_right"} {
+		for _, axis := range diff.Get(name).(*schema.Set).List() {
+			if err := checkAxisOptions(name, axis.(map[string]interface{})); err != nil {
+				return err
+			}
+		}
+	}
+	return checkVizOptionsLabels(diff.Get("viz_options").(*schema.Set).List())
+}
+
+/*
+  viz_options is a set, so nothing prevents two blocks for the same plot, e.g. with different axes. Only
+  one of them would be applied.
+*/
+func checkVizOptionsLabels(vizOptions []interface{}) error {
+	seen := make(map[string]bool)
+	for _, vizOption := range vizOptions {
+		label := vizOption.(map[string]interface{})["label"].(string)
+		if seen[label] {
+			return fmt.Errorf("viz_options: several blocks for the label %s", label)
+		}
+		seen[label] = true
+	}
+	return nil
+}
+
+/*
+  Events are shown only when program_text publishes them under the label of their event_options
+*/
+func validateTimeChartEventOptions(diff *schema.ResourceDiff, meta interface{}) error {
+	if !diff.NewValueKnown("program_text") || !diff.NewValueKnown("event_options") {
+		return nil
+	}
+	return checkEventOptionsLabels(diff.Get("program_text").(string), diff.Get("event_options").(*schema.Set).List())
+}
+
+func checkEventOptionsLabels(programText string, eventOptions []interface{}) error {
+	labels, ok := getPublishLabels(programText)
+	if !ok {
+		return nil
+	}
+	for _, eventOption := range eventOptions {
+		label := eventOption.(map[string]interface{})["label"].(string)
+		if !labels[label] {
+			return fmt.Errorf("event_options: the label %s is not published by program_text", label)
+		}
+	}
+	return nil
+}
+
+/*
+  Only area and column plots are stacked, stacked has no effect on line charts and histograms
+*/
+func validateTimeChartStacked(diff *schema.ResourceDiff, meta interface{}) error {
+	if !diff.Get("stacked").(bool) || !diff.NewValueKnown("plot_type") || !diff.NewValueKnown("viz_options") {
+		return nil
+	}
+	return checkStackedPlotTypes(diff.Get("plot_type").(string), diff.Get("viz_options").(*schema.Set).List())
+}
+
+func checkStackedPlotTypes(plotType string, vizOptions []interface{}) error {
+	plotTypes := []string{plotType}
+	for _, vizOption := range vizOptions {
+		if val, ok := vizOption.(map[string]interface{})["plot_type"].(string); ok && val != "" {
+			plotTypes = append(plotTypes, val)
+		}
 	}
+	for _, plotType := range plotTypes {
+		if plotType == "AreaChart" || plotType == "ColumnChart" {
+			return nil
+		}
+	}
+	return fmt.Errorf("stacked requires plot_type to be AreaChart or ColumnChart, for the chart or one of its viz_options")
+}
+
+func checkAxisOptions(name string, axis map[string]interface{}) error {
+	minValue, _ := axis["min_value"].(float64)
+	maxValue, _ := axis["max_value"].(float64)
+	if minValue > maxValue {
+		return fmt.Errorf("%s: min_value (%v) must not be greater than max_value (%v)", name, minValue, maxValue)
+	}
+	lowWatermark, _ := axis["low_watermark"].(float64)
+	highWatermark, _ := axis["high_watermark"].(float64)
+	if lowWatermark > highWatermark {
+		return fmt.Errorf("%s: low_watermark (%v) must not be greater than high_watermark (%v)", name, lowWatermark, highWatermark)
+	}
+	if label, _ := axis["high_watermark_label"].(string); label != "" && highWatermark == math.MaxFloat32 {
+		return fmt.Errorf("%s: high_watermark_label requires high_watermark", name)
+	}
+	if label, _ := axis["low_watermark_label"].(string); label != "" && lowWatermark == -math.MaxFloat32 {
+		return fmt.Errorf("%s: low_watermark_label requires low_watermark", name)
+	}
+	return nil
 }
 
 /*
   Use Resource object to construct json payload in order to create a time chart
 */
-func getPayloadTimeChart(d *schema.ResourceData) ([]byte, error) {
+func getPayloadTimeChart(d *schema.ResourceData, config *signalformConfig) ([]byte, error) {
 	payload := map[string]interface{}{
 		"name":        d.Get("name").(string),
 		"description": d.Get("description").(string),
 		"programText": d.Get("program_text").(string),
 	}
 
-	viz := getTimeChartOptions(d)
+	viz := getTimeChartOptions(d, config)
 	if axesOptions := getAxesOptions(d); len(axesOptions) > 0 {
 		viz["axes"] = axesOptions
 	}
@@ -445,15 +595,13 @@ func getPayloadTimeChart(d *schema.ResourceData) ([]byte, error) {
 	if vizOptions := getPerSignalVizOptions(d); len(vizOptions) > 0 {
 		viz["publishLabelOptions"] = vizOptions
 	}
+	if eventOptions := getEventPublishLabelOptions(d); len(eventOptions) > 0 {
+		viz["eventPublishLabelOptions"] = eventOptions
+	}
 	if onChartLegendDim, ok := d.GetOk("on_chart_legend_dimension"); ok {
-		if onChartLegendDim == "metric" {
-			onChartLegendDim = "sf_originatingMetric"
-		} else if onChartLegendDim == "plot_label" {
-			onChartLegendDim = "sf_metric"
-		}
 		viz["onChartLegendOptions"] = map[string]interface{}{
 			"showLegend":        true,
-			"dimensionInLegend": onChartLegendDim,
+			"dimensionInLegend": getLegendProperty(onChartLegendDim.(string)),
 		}
 	}
 	if len(viz) > 0 {
@@ -463,6 +611,27 @@ func getPayloadTimeChart(d *schema.ResourceData) ([]byte, error) {
 	return json.Marshal(payload)
 }
 
+func getEventPublishLabelOptions(d *schema.ResourceData) []map[string]interface{} {
+	events := d.Get("event_options").(*schema.Set).List()
+	event_list := make([]map[string]interface{}, len(events))
+	for i, event := range events {
+		event := event.(map[string]interface{})
+		item := make(map[string]interface{})
+
+		item["label"] = event["label"].(string)
+		if val, ok := event["display_name"].(string); ok && val != "" {
+			item["displayName"] = val
+		}
+		if val, ok := event["color"].(string); ok {
+			if elem, ok := PaletteColors[val]; ok {
+				item["paletteIndex"] = elem
+			}
+		}
+		event_list[i] = item
+	}
+	return event_list
+}
+
 func getPerSignalVizOptions(d *schema.ResourceData) []map[string]interface{} {
 	viz := d.Get("viz_options").(*schema.Set).List()
 	viz_list := make([]map[string]interface{}, len(viz))
@@ -471,6 +640,9 @@ func getPerSignalVizOptions(d *schema.ResourceData) []map[string]interface{} {
 		item := make(map[string]interface{})
 
 		item["label"] = v["label"].(string)
+		if val, ok := v["display_name"].(string); ok && val != "" {
+			item["displayName"] = val
+		}
 		if val, ok := v["color"].(string); ok {
 			if elem, ok := PaletteColors[val]; ok {
 				item["paletteIndex"] = elem
@@ -557,8 +729,7 @@ func getSingleAxisOptions(axisOpt map[string]interface{}) map[string]interface{}
 		item["lowWatermarkLabel"] = val.(string)
 	}
 
-	// special case: the axis object might exist, but it has no keys except
-	// watermarks
+	// special case: the axis object might exist, but it has no keys
 	// in this case, we don't want to report an axis object to sfx at all
 	if len(item) == 0 {
 		return nil
@@ -566,7 +737,7 @@ func getSingleAxisOptions(axisOpt map[string]interface{}) map[string]interface{}
 	return item
 }
 
-func getTimeChartOptions(d *schema.ResourceData) map[string]interface{} {
+func getTimeChartOptions(d *schema.ResourceData, config *signalformConfig) map[string]interface{} {
 	viz := make(map[string]interface{})
 	viz["type"] = "TimeSeriesChart"
 	if val, ok := d.GetOk("unit_prefix"); ok {
@@ -593,19 +764,20 @@ func getTimeChartOptions(d *schema.ResourceData) map[string]interface{} {
 	if val, ok := d.GetOk("minimum_resolution"); ok {
 		programOptions["minimumResolution"] = val.(int) * 1000
 	}
-	if val, ok := d.GetOk("max_delay"); ok {
-		programOptions["maxDelay"] = val.(int) * 1000
-	}
-	if val, ok := d.GetOk("disable_sampling"); ok {
-		programOptions["disableSampling"] = val.(bool)
+	if maxDelay, ok := getMaxDelay(d.GetOkExists, config); ok {
+		programOptions["maxDelay"] = maxDelay * 1000
 	}
+	programOptions["disableSampling"] = getDisableSampling(d, config)
 	if len(programOptions) > 0 {
 		viz["programOptions"] = programOptions
 	}
+	if val, ok := d.GetOk("timezone"); ok {
+		viz["timezone"] = val.(string)
+	}
 
 	timeMap := make(map[string]interface{})
 	if val, ok := d.GetOk("time_range"); ok {
-		if ms, err := fromRangeToMilliSeconds(val.(string)); err == nil {
+		if ms, err := sfxtime.ToMilliseconds(val.(string)); err == nil {
 			timeMap["range"] = ms
 			timeMap["type"] = "relative"
 		}
@@ -650,36 +822,36 @@ func getTimeChartOptions(d *schema.ResourceData) map[string]interface{} {
 
 func timechartCreate(d *schema.ResourceData, meta interface{}) error {
 	config := meta.(*signalformConfig)
-	payload, err := getPayloadTimeChart(d)
+	payload, err := getPayloadTimeChart(d, config)
 	if err != nil {
 		return fmt.Errorf("Failed creating json payload: %s", err.Error())
 	}
 
-	return resourceCreate(CHART_API_URL, config.AuthToken, payload, d)
+	return resourceCreate(CHART_API_URL, config, payload, d)
 }
 
 func timechartRead(d *schema.ResourceData, meta interface{}) error {
 	config := meta.(*signalformConfig)
 	url := fmt.Sprintf("%s/%s", CHART_API_URL, d.Id())
 
-	return resourceRead(url, config.AuthToken, d)
+	return resourceRead(url, config, d)
 }
 
 func timechartUpdate(d *schema.ResourceData, meta interface{}) error {
 	config := meta.(*signalformConfig)
-	payload, err := getPayloadTimeChart(d)
+	payload, err := getPayloadTimeChart(d, config)
 	if err != nil {
 		return fmt.Errorf("Failed creating json payload: %s", err.Error())
 	}
 	url := fmt.Sprintf("%s/%s", CHART_API_URL, d.Id())
 
-	return resourceUpdate(url, config.AuthToken, payload, d)
+	return resourceUpdate(url, config, payload, d)
 }
 
 func timechartDelete(d *schema.ResourceData, meta interface{}) error {
 	config := meta.(*signalformConfig)
 	url := fmt.Sprintf("%s/%s", CHART_API_URL, d.Id())
-	return resourceDelete(url, config.AuthToken, d)
+	return resourceDelete(url, config, d)
 }
 
 /*
@@ -719,7 +891,7 @@ func validateUnitTimeChart(v interface{}, k string) (we []string, errors []error
 		"Byte",
 		"Kibibyte",
 		"Mebibyte",
-		"Gigibyte",
+		"Gibibyte",
 		"Tebibyte",
 		"Pebibyte",
 		"Exbibyte",
@@ -1,7 +1,9 @@
 package signalform
 
 import (
+	"encoding/json"
 	"github.com/stretchr/testify/assert"
+	"math"
 	"testing"
 )
 
@@ -16,3 +18,169 @@ func TestValidatePlotTypeTimeChartNotAllowed(t *testing.T) {
 	_, errors := validatePlotTypeTimeChart("absolute", "plot_type")
 	assert.Equal(t, len(errors), 1)
 }
+
+func TestCheckAxisOptions(t *testing.T) {
+	axis := func(min float64, max float64, low float64, high float64) map[string]interface{} {
+		return map[string]interface{}{"min_value": min, "max_value": max, "low_watermark": low, "high_watermark": high}
+	}
+	unset := float64(math.MaxFloat32)
+
+	assert.Nil(t, checkAxisOptions("axis_left", axis(-unset, unset, -unset, unset)))
+	assert.Nil(t, checkAxisOptions("axis_left", axis(0, 100, 10, 90)))
+	assert.Nil(t, checkAxisOptions("axis_left", axis(-unset, unset, 95, unset)))
+	assert.NotNil(t, checkAxisOptions("axis_left", axis(100, 0, -unset, unset)))
+	assert.Nil(t, checkAxisOptions("axis_left", axis(200, unset, -unset, unset)))
+	assert.NotNil(t, checkAxisOptions("axis_right", axis(0, 100, 90, 10)))
+
+	sla := axis(-unset, unset, -unset, 200)
+	sla["high_watermark_label"] = "SLA"
+	assert.Nil(t, checkAxisOptions("axis_left", sla))
+	sla["high_watermark"] = unset
+	assert.NotNil(t, checkAxisOptions("axis_left", sla))
+	floor := axis(-unset, unset, -unset, unset)
+	floor["low_watermark_label"] = "floor"
+	assert.NotNil(t, checkAxisOptions("axis_left", floor))
+}
+
+func TestGetSingleAxisOptionsWatermarks(t *testing.T) {
+	unset := float64(math.MaxFloat32)
+	item := getSingleAxisOptions(map[string]interface{}{
+		"min_value": -unset, "max_value": unset, "label": "Latency",
+		"high_watermark": 200.0, "high_watermark_label": "SLA", "low_watermark": -unset, "low_watermark_label": "",
+	})
+	assert.Equal(t, 200.0, item["highWatermark"])
+	assert.Equal(t, "SLA", item["highWatermarkLabel"])
+	assert.Nil(t, item["lowWatermark"])
+}
+
+func TestCheckVizOptionsLabels(t *testing.T) {
+	latency := map[string]interface{}{"label": "latency", "axis": "left"}
+	requests := map[string]interface{}{"label": "requests", "axis": "right"}
+	assert.Nil(t, checkVizOptionsLabels([]interface{}{latency, requests}))
+	assert.NotNil(t, checkVizOptionsLabels([]interface{}{latency, requests, map[string]interface{}{"label": "latency", "axis": "right"}}))
+}
+
+func TestGetPerSignalVizOptions(t *testing.T) {
+	d := timeChartResource().TestResourceData()
+	d.Set("viz_options", []interface{}{
+		map[string]interface{}{"label": "p99", "display_name": "Latency (p99)", "axis": "right", "plot_type": "AreaChart"},
+	})
+	assert.Equal(t, []map[string]interface{}{
+		map[string]interface{}{"label": "p99", "displayName": "Latency (p99)", "yAxis": 1, "plotType": "AreaChart"},
+	}, getPerSignalVizOptions(d))
+}
+
+func TestValidateUnitTimeChart(t *testing.T) {
+	for _, value := range []string{"Byte", "Gibibyte", "Millisecond", "Week"} {
+		_, errors := validateUnitTimeChart(value, "value_unit")
+		assert.Equal(t, 0, len(errors), value)
+	}
+	for _, value := range []string{"Gigibyte", "Gigabyte", "ms"} {
+		_, errors := validateUnitTimeChart(value, "value_unit")
+		assert.Equal(t, 1, len(errors), value)
+	}
+}
+
+func TestGetLegendOptionsFields(t *testing.T) {
+	d := timeChartResource().TestResourceData()
+	assert.Nil(t, getLegendOptions(d))
+
+	d.Set("legend_options_fields", []interface{}{
+		map[string]interface{}{"property": "host", "enabled": true},
+		map[string]interface{}{"property": "metric", "enabled": false},
+	})
+	assert.Equal(t, map[string]interface{}{
+		"fields": []map[string]interface{}{
+			map[string]interface{}{"property": "host", "enabled": true},
+			map[string]interface{}{"property": "sf_originatingMetric", "enabled": false},
+		},
+	}, getLegendOptions(d))
+}
+
+func TestGetPayloadTimeChartOnChartLegend(t *testing.T) {
+	for property, expected := range map[string]string{"plot_label": "sf_metric", "metric": "sf_originatingMetric", "host": "host"} {
+		d := timeChartResource().TestResourceData()
+		d.Set("name", "chart")
+		d.Set("on_chart_legend_dimension", property)
+
+		payload, err := getPayloadTimeChart(d, &signalformConfig{})
+		assert.Nil(t, err)
+		decoded := map[string]interface{}{}
+		json.Unmarshal(payload, &decoded)
+		assert.Equal(t, map[string]interface{}{"showLegend": true, "dimensionInLegend": expected}, decoded["options"].(map[string]interface{})["onChartLegendOptions"])
+	}
+}
+
+func TestCheckEventOptionsLabels(t *testing.T) {
+	programText := "data('cpu.utilization').publish(label='cpu')\nevents(eventType='deploy').publish(label='deploys')"
+	assert.Nil(t, checkEventOptionsLabels(programText, []interface{}{
+		map[string]interface{}{"label": "deploys"},
+	}))
+
+	err := checkEventOptionsLabels(programText, []interface{}{
+		map[string]interface{}{"label": "deploy"},
+	})
+	assert.Contains(t, err.Error(), "the label deploy is not published")
+
+	// Labels are unknown when published from variables
+	assert.Nil(t, checkEventOptionsLabels("label = 'deploys'\nevents(eventType='deploy').publish(label=label)", []interface{}{
+		map[string]interface{}{"label": "deploy"},
+	}))
+}
+
+func TestGetPayloadTimeChartEventOptions(t *testing.T) {
+	d := timeChartResource().TestResourceData()
+	d.Set("name", "Latency")
+	d.Set("program_text", "events(eventType='deploy').publish(label='deploys')")
+	d.Set("event_options", []interface{}{
+		map[string]interface{}{"label": "deploys", "display_name": "Deploys", "color": "orange"},
+	})
+
+	payload, err := getPayloadTimeChart(d, &signalformConfig{})
+	assert.Nil(t, err)
+	var chart map[string]interface{}
+	assert.Nil(t, json.Unmarshal(payload, &chart))
+	options := chart["options"].(map[string]interface{})
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"label": "deploys", "displayName": "Deploys", "paletteIndex": 5.0},
+	}, options["eventPublishLabelOptions"])
+}
+
+func TestGetPayloadTimeChartTimezone(t *testing.T) {
+	d := timeChartResource().TestResourceData()
+	d.Set("name", "Latency")
+	d.Set("program_text", "data('app.latency').publish(label='latency')")
+	d.Set("timezone", "Europe/Paris")
+	d.Set("time_range", "-1h")
+
+	payload, err := getPayloadTimeChart(d, &signalformConfig{})
+	assert.Nil(t, err)
+	var chart map[string]interface{}
+	assert.Nil(t, json.Unmarshal(payload, &chart))
+	options := chart["options"].(map[string]interface{})
+	assert.Equal(t, "Europe/Paris", options["timezone"])
+	assert.Equal(t, map[string]interface{}{"disableSampling": false}, options["programOptions"])
+	assert.Equal(t, map[string]interface{}{"type": "relative", "range": 3600000.0}, options["time"])
+}
+
+func TestGetTimeChartOptionsDisableSampling(t *testing.T) {
+	d := timeChartResource().TestResourceData()
+	viz := getTimeChartOptions(d, &signalformConfig{})
+	assert.Equal(t, false, viz["programOptions"].(map[string]interface{})["disableSampling"])
+
+	d.Set("disable_sampling", true)
+	viz = getTimeChartOptions(d, &signalformConfig{})
+	assert.Equal(t, true, viz["programOptions"].(map[string]interface{})["disableSampling"])
+}
+
+func TestCheckStackedPlotTypes(t *testing.T) {
+	assert.Nil(t, checkStackedPlotTypes("AreaChart", []interface{}{}))
+	assert.Nil(t, checkStackedPlotTypes("ColumnChart", []interface{}{}))
+	assert.Nil(t, checkStackedPlotTypes("LineChart", []interface{}{
+		map[string]interface{}{"label": "cpu", "plot_type": "AreaChart"},
+	}))
+	assert.NotNil(t, checkStackedPlotTypes("", []interface{}{}))
+	assert.NotNil(t, checkStackedPlotTypes("LineChart", []interface{}{
+		map[string]interface{}{"label": "cpu", "plot_type": ""},
+	}))
+}
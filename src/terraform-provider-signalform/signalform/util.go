@@ -5,10 +5,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"log"
 	"math"
 	"net/http"
+	"net/url"
 	"regexp"
-	"strconv"
+	"sort"
 	"strings"
 
 	"github.com/hashicorp/terraform/helper/schema"
@@ -21,6 +23,21 @@ const (
 	CHART_URL     = "https://app.signalfx.com/#/chart/<id>"
 )
 
+// Version of the provider in the User-Agent of requests, set at build time with
+// -ldflags "-X terraform-provider-signalform/signalform.ProviderVersion=<version>"
+var ProviderVersion = "dev"
+
+// Fields set by SignalFx, which must not be sent back when writing an object
+var readOnlyFields = []string{"id", "created", "creator", "lastUpdated", "lastUpdatedBy"}
+
+// Base URL of the SignalFx application in the default resource_url values
+const DEFAULT_APP_URL = "https://app.signalfx.com"
+
+var timezoneRegexp = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_+-]*(/[A-Za-z0-9_+-]+)*$`)
+
+// Message of the 403 SignalFx answers for the endpoints of a feature the organization doesn't have
+var featureNotEnabledRegexp = regexp.MustCompile(`(?i)\bfeature [\w.-]+ is not enabled\b`)
+
 var ChartColors = map[string]string{
 	"gray":       "#999999",
 	"blue":       "#0077c2",
@@ -38,20 +55,28 @@ var ChartColors = map[string]string{
 /*
   Utility function that wraps http calls to SignalFx
 */
-func sendRequest(method string, url string, token string, payload []byte) (int, []byte, error) {
+func sendRequest(method string, url string, config *signalformConfig, payload []byte) (int, []byte, error) {
+	release := waitForRequestSlot()
+	defer release()
 	client := &http.Client{}
 
 	req, err := http.NewRequest(method, url, bytes.NewReader(payload))
 	req.Header.Add("Content-Type", "application/json")
-	req.Header.Add("X-SF-Token", token)
+	req.Header.Add("X-SF-Token", config.AuthToken)
+	req.Header.Add("User-Agent", "terraform-provider-signalform/"+ProviderVersion)
+	if config.RequestTag != "" {
+		req.Header.Add("X-SF-Request-Tag", config.RequestTag)
+	}
 
 	resp, err := client.Do(req)
 	if err != nil {
+		recordExchange(method, url, payload, -1, nil, err)
 		return -1, nil, fmt.Errorf("Failed sending %s request to Signalfx: %s", method, err.Error())
 	}
 
 	body, err := ioutil.ReadAll(resp.Body)
 	defer resp.Body.Close()
+	recordExchange(method, url, payload, resp.StatusCode, body, err)
 
 	if err != nil {
 		return resp.StatusCode, nil, fmt.Errorf("Failed reading response body from %s request: %s", method, err.Error())
@@ -60,28 +85,120 @@ func sendRequest(method string, url string, token string, payload []byte) (int,
 	return resp.StatusCode, body, nil
 }
 
+/*
+  max_delay of a chart or detector, falling back to the provider default. false when neither is set. An
+  explicit 0 (the automatic delay) overrides the provider default. Takes the GetOkExists of either the
+  ResourceData or the ResourceDiff, so that plan time checks see the value that is sent.
+*/
+func getMaxDelay(getOkExists func(string) (interface{}, bool), config *signalformConfig) (int, bool) {
+	if val, ok := getOkExists("max_delay"); ok {
+		return val.(int), true
+	}
+	return config.DefaultMaxDelay, config.DefaultMaxDelay > 0
+}
+
+/*
+  disable_sampling of a chart or detector, falling back to the provider default. An explicit false
+  overrides a provider default of true.
+*/
+func getDisableSampling(d *schema.ResourceData, config *signalformConfig) bool {
+	if val, ok := d.GetOkExists("disable_sampling"); ok {
+		return val.(bool)
+	}
+	return config.DefaultDisableSampling
+}
+
 /*
   Validates max_delay field; it must be between 0 and 900 seconds (15m in).
 */
 func validateMaxDelayValue(v interface{}, k string) (we []string, errors []error) {
 	value := v.(int)
 	if value < 0 || value > 900 {
-		errors = append(errors, fmt.Errorf("%d not allowed; max_delay must be >= 0 && <= 900", value))
+		errors = append(errors, fmt.Errorf("%d not allowed; %s must be >= 0 && <= 900", value, k))
 	}
 	return
 }
 
 /*
-  Validates that sort_by field start with either + or -.
+  Charts without published streams render empty, and the API accepts them silently. This checks at plan time
+  that program_text contains at least one publish call, unless disabled in the provider configuration.
+*/
+func validateProgramTextPublishes(diff *schema.ResourceDiff, meta interface{}) error {
+	if config, ok := meta.(*signalformConfig); ok && !config.ValidateProgramPublish {
+		return nil
+	}
+	if !diff.NewValueKnown("program_text") {
+		return nil
+	}
+	programText := diff.Get("program_text").(string)
+	if !programTextPublishes(programText) {
+		return fmt.Errorf("program_text of %s does not publish any stream; add a publish() call or set validate_program_publish = false in the provider", diff.Get("name"))
+	}
+	return nil
+}
+
+func programTextPublishes(programText string) bool {
+	return regexp.MustCompile(`publish\s*\(`).MatchString(programText)
+}
+
+/*
+  Validates that sort_by field start with either + or -, followed by a property.
 */
 func validateSortBy(v interface{}, k string) (we []string, errors []error) {
 	value := v.(string)
 	if !strings.HasPrefix(value, "+") && !strings.HasPrefix(value, "-") {
 		errors = append(errors, fmt.Errorf("%s not allowed; must start either with + or - (ascending or descending)", value))
+	} else if len(strings.TrimSpace(value[1:])) == 0 {
+		errors = append(errors, fmt.Errorf("%s not allowed; the property to sort by must follow the + or - (e.g. -value)", value))
 	}
 	return
 }
 
+/*
+  Schema of the color_scale blocks of single value, list and heatmap charts
+*/
+func colorScaleSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeSet,
+		Optional:    true,
+		Description: "Single color range including both the color to display for that range and the borders of the range",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"gt": &schema.Schema{
+					Type:        schema.TypeFloat,
+					Optional:    true,
+					Default:     math.MaxFloat32,
+					Description: "Indicates the lower threshold non-inclusive value for this range",
+				},
+				"gte": &schema.Schema{
+					Type:        schema.TypeFloat,
+					Optional:    true,
+					Default:     math.MaxFloat32,
+					Description: "Indicates the lower threshold inclusive value for this range",
+				},
+				"lt": &schema.Schema{
+					Type:        schema.TypeFloat,
+					Optional:    true,
+					Default:     math.MaxFloat32,
+					Description: "Indicates the upper threshold non-inclusive value for this range",
+				},
+				"lte": &schema.Schema{
+					Type:        schema.TypeFloat,
+					Optional:    true,
+					Default:     math.MaxFloat32,
+					Description: "Indicates the upper threshold inclusive value for this range",
+				},
+				"color": &schema.Schema{
+					Type:         schema.TypeString,
+					Required:     true,
+					Description:  "The color to use. Must be either \"gray\", \"blue\", \"navy\", \"orange\", \"yellow\", \"magenta\", \"purple\", \"violet\", \"lilac\", \"green\", \"aquamarine\"",
+					ValidateFunc: validateHeatmapChartColor,
+				},
+			},
+		},
+	}
+}
+
 /*
 	Get Color Scale Options
 */
@@ -106,48 +223,218 @@ func getColorScaleOptions(d *schema.ResourceData) []interface{} {
 		if scale["lte"].(float64) != math.MaxFloat32 {
 			options["lte"] = scale["lte"].(float64)
 		}
-		paletteIndex := 0
-		for colorName, _ := range ChartColors {
-			if colorName == scale["color"].(string) {
-				break
-			}
-			paletteIndex++
-		}
-		options["paletteIndex"] = paletteIndex
+		// SignalFx expects the index of the color in the plot palette, which has all the color_scale colors
+		options["paletteIndex"] = PaletteColors[scale["color"].(string)]
 		item[i] = options
 	}
 	return item
 }
 
+/*
+  Color scale blocks are sent as they are: a block without any bound, or with both an inclusive and a
+  non-inclusive bound on the same side, is accepted by the API and colors the values unexpectedly.
+*/
+func validateColorScale(diff *schema.ResourceDiff, meta interface{}) error {
+	if !diff.NewValueKnown("color_scale") {
+		return nil
+	}
+	return checkColorScale(diff.Get("color_scale").(*schema.Set).List())
+}
+
+func checkColorScale(colorScale []interface{}) error {
+	for _, scale := range colorScale {
+		scale := scale.(map[string]interface{})
+		isSet := func(bound string) bool {
+			value, ok := scale[bound].(float64)
+			return ok && value != math.MaxFloat32
+		}
+		if !isSet("gt") && !isSet("gte") && !isSet("lt") && !isSet("lte") {
+			return fmt.Errorf("color_scale: the %s range must set at least one of gt, gte, lt or lte", scale["color"])
+		}
+		if isSet("gt") && isSet("gte") {
+			return fmt.Errorf("color_scale: the %s range must not set both gt and gte", scale["color"])
+		}
+		if isSet("lt") && isSet("lte") {
+			return fmt.Errorf("color_scale: the %s range must not set both lt and lte", scale["color"])
+		}
+	}
+	return nil
+}
+
+/*
+  color_by = "Scale" without color_scale would silently fall back to the default coloring of single value and
+  list charts
+*/
+func validateColorByScale(diff *schema.ResourceDiff, meta interface{}) error {
+	if diff.NewValueKnown("color_by") && diff.NewValueKnown("color_scale") &&
+		diff.Get("color_by").(string) == "Scale" && diff.Get("color_scale").(*schema.Set).Len() == 0 {
+		return fmt.Errorf("color_scale: at least one range is required when color_by is Scale")
+	}
+	return validateColorScale(diff, meta)
+}
+
+/*
+  Validates the color_by field of single value and list charts
+*/
+func validateColorBy(v interface{}, k string) (we []string, errors []error) {
+	value := v.(string)
+	allowedWords := []string{"Metric", "Dimension", "Scale"}
+	for _, word := range allowedWords {
+		if value == word {
+			return
+		}
+	}
+	if value == "Value" {
+		errors = append(errors, fmt.Errorf("%s not allowed; did you mean Scale? (Color by Value in the UI)", value))
+		return
+	}
+	errors = append(errors, fmt.Errorf("%s not allowed; must be one of: %s", value, strings.Join(allowedWords, ", ")))
+	return
+}
+
+/*
+  Searches objects with the search API of SignalFx, query being its parameters (e.g. name)
+*/
+func searchSignalFxObjects(apiUrl string, query url.Values, config *signalformConfig) ([]map[string]interface{}, error) {
+	status_code, resp_body, err := sendRequest("GET", fmt.Sprintf("%s?%s", apiUrl, query.Encode()), config, nil)
+	if err != nil {
+		return nil, err
+	}
+	if status_code != 200 {
+		return nil, fmt.Errorf("SignalFx returned status %d: \n%s", status_code, resp_body)
+	}
+	page := struct {
+		Results []map[string]interface{} `json:"results"`
+	}{}
+	if err := json.Unmarshal(resp_body, &page); err != nil {
+		return nil, fmt.Errorf("Failed unmarshaling: %s", err.Error())
+	}
+	return page.Results, nil
+}
+
+/*
+  The search API matches names partially, only exact matches are kept. kind names the objects in errors.
+*/
+func findObjectByName(kind string, objects []map[string]interface{}, name string) (map[string]interface{}, error) {
+	matches := make([]map[string]interface{}, 0)
+	for _, object := range objects {
+		if object["name"] == name {
+			matches = append(matches, object)
+		}
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("No %s named %s", kind, name)
+	}
+	if len(matches) > 1 {
+		ids := make([]interface{}, len(matches))
+		for i, match := range matches {
+			ids[i] = match["id"]
+		}
+		return nil, fmt.Errorf("%d %ss are named %s: %v", len(matches), kind, name, ids)
+	}
+	return matches[0], nil
+}
+
+/*
+  Keeps the objects having all the tags
+*/
+func filterObjectsByTags(objects []map[string]interface{}, tags []string) []map[string]interface{} {
+	tagged := make([]map[string]interface{}, 0)
+	for _, object := range objects {
+		objectTags := make(map[string]bool)
+		if values, ok := object["tags"].([]interface{}); ok {
+			for _, tag := range values {
+				if tag, ok := tag.(string); ok {
+					objectTags[tag] = true
+				}
+			}
+		}
+		hasTags := true
+		for _, tag := range tags {
+			hasTags = hasTags && objectTags[tag]
+		}
+		if hasTags {
+			tagged = append(tagged, object)
+		}
+	}
+	return tagged
+}
+
+/*
+  Tells apart an endpoint which is not available for the organization (unknown route or feature not enabled)
+  from a missing resource, for which SignalFx answers with "<resource> <id> not found". Other 403s, e.g.
+  for an invalid token or missing permissions, are real errors.
+*/
+func isUnsupportedEndpoint(statusCode int, body []byte) bool {
+	if statusCode == 404 {
+		return strings.Contains(strings.ToLower(string(body)), "page not found")
+	}
+	if statusCode == 403 {
+		return featureNotEnabledRegexp.Match(body)
+	}
+	return false
+}
+
 /*
   Send a GET to get the current state of the resource. It just checks if the lastUpdated timestamp is
   later than the timestamp saved in the resource. If so, the resource has been modified in some way
   in the UI, and should be recreated. This is signaled by setting synced to false, meaning if synced is set to
   true in the tf configuration, it will update the resource to achieve the desired state.
 */
-func resourceRead(url string, sfxToken string, d *schema.ResourceData) error {
-	status_code, resp_body, err := sendRequest("GET", url, sfxToken, nil)
+func resourceRead(url string, config *signalformConfig, d *schema.ResourceData) error {
+	return resourceReadWithState(url, config, d, nil)
+}
+
+/*
+  Same as resourceRead, but hands the decoded API response to setState (if not nil) so that a resource
+  can reflect server-side values in its state, e.g. when it is being imported.
+*/
+func resourceReadWithState(url string, config *signalformConfig, d *schema.ResourceData, setState func(map[string]interface{}, *schema.ResourceData) error) error {
+	status_code, resp_body, err := sendRequest("GET", url, config, nil)
 	if status_code == 200 {
-		mapped_resp := map[string]interface{}{}
-		err = json.Unmarshal(resp_body, &mapped_resp)
+		// The API has no conditional GET (no ETag, no If-Modified-Since), so the whole object is always
+		// returned. Only id and lastUpdated are decoded, unless the resource reflects more of the response,
+		// in which case the response is decoded once and both are taken from it.
+		header := struct {
+			Id          string  `json:"id"`
+			LastUpdated float64 `json:"lastUpdated"`
+		}{}
+		var mapped_resp map[string]interface{}
+		if setState != nil {
+			err = json.Unmarshal(resp_body, &mapped_resp)
+			header.Id, _ = mapped_resp["id"].(string)
+			header.LastUpdated, _ = mapped_resp["lastUpdated"].(float64)
+		} else {
+			err = json.Unmarshal(resp_body, &header)
+		}
 		if err != nil {
 			return fmt.Errorf("Failed unmarshaling for the resource %s during read: %s", d.Get("name"), err.Error())
 		}
 		// This implies the resource was modified in the Signalfx UI and therefore it is not synced with Signalform
-		last_updated := mapped_resp["lastUpdated"].(float64)
-		if last_updated > (d.Get("last_updated").(float64) + OFFSET) {
+		if header.LastUpdated > (d.Get("last_updated").(float64) + OFFSET) {
 			d.Set("synced", false)
-			d.Set("last_updated", last_updated)
+			d.Set("last_updated", header.LastUpdated)
 		}
 		var resource_url string
 		if val, ok := d.GetOk("resource_url"); ok {
-			resource_url = strings.Replace(fmt.Sprintf("%s", val), "<id>", mapped_resp["id"].(string), 1)
+			resource_url = getResourceUrl(fmt.Sprintf("%s", val), header.Id, config)
 		} else {
 			resource_url = "DUMMY"
 		}
 		d.Set("url", resource_url)
+		if setState != nil {
+			if err := setState(mapped_resp, d); err != nil {
+				return fmt.Errorf("Failed reading state for the resource %s: %s", d.Get("name"), err.Error())
+			}
+		}
 	} else {
-		if status_code == 404 && strings.Contains(string(resp_body), " not found") {
+		if isUnsupportedEndpoint(status_code, resp_body) {
+			if config.IgnoreUnsupported {
+				log.Printf("[WARN] Skipping read of %s: %s is not available for the organization", d.Get("name"), url)
+				return nil
+			}
+			return fmt.Errorf("For the resource %s SignalFx returned status %d for %s, which usually means the feature is not enabled for your organization. Contact SignalFx to enable it, or set ignore_unsupported = true in the provider to skip reading such resources: \n%s", d.Get("name"), status_code, url, resp_body)
+		} else if status_code == 404 && strings.Contains(string(resp_body), " not found") {
 			// This implies that the resouce was deleted in the Signalfx UI and therefore we need to recreate it
 			d.SetId("")
 		} else {
@@ -161,8 +448,8 @@ func resourceRead(url string, sfxToken string, d *schema.ResourceData) error {
 /*
   Fetches payload specified in terraform configuration and creates a resource
 */
-func resourceCreate(url string, sfxToken string, payload []byte, d *schema.ResourceData) error {
-	status_code, resp_body, err := sendRequest("POST", url, sfxToken, payload)
+func resourceCreate(url string, config *signalformConfig, payload []byte, d *schema.ResourceData) error {
+	status_code, resp_body, err := sendRequest("POST", url, config, payload)
 	if status_code == 200 {
 		mapped_resp := map[string]interface{}{}
 		err = json.Unmarshal(resp_body, &mapped_resp)
@@ -173,7 +460,7 @@ func resourceCreate(url string, sfxToken string, payload []byte, d *schema.Resou
 		d.Set("last_updated", mapped_resp["lastUpdated"].(float64))
 		d.Set("synced", true)
 		// Replace "<id>" with the actual Resource ID
-		resource_url := strings.Replace(fmt.Sprintf("%s", d.Get("resource_url")), "<id>", mapped_resp["id"].(string), 1)
+		resource_url := getResourceUrl(fmt.Sprintf("%s", d.Get("resource_url")), mapped_resp["id"].(string), config)
 		d.Set("url", resource_url)
 	} else {
 		return fmt.Errorf("For the resource %s SignalFx returned status %d: \n%s", d.Get("name"), status_code, resp_body)
@@ -184,8 +471,8 @@ func resourceCreate(url string, sfxToken string, payload []byte, d *schema.Resou
 /*
   Fetches payload specified in terraform configuration and creates chart
 */
-func resourceUpdate(url string, sfxToken string, payload []byte, d *schema.ResourceData) error {
-	status_code, resp_body, err := sendRequest("PUT", url, sfxToken, payload)
+func resourceUpdate(url string, config *signalformConfig, payload []byte, d *schema.ResourceData) error {
+	status_code, resp_body, err := sendRequest("PUT", url, config, payload)
 	if status_code == 200 {
 		mapped_resp := map[string]interface{}{}
 		err = json.Unmarshal(resp_body, &mapped_resp)
@@ -195,7 +482,7 @@ func resourceUpdate(url string, sfxToken string, payload []byte, d *schema.Resou
 		// If the resource was updated successfully with Signalform configs, it is now synced with Signalfx
 		d.Set("synced", true)
 		d.Set("last_updated", mapped_resp["lastUpdated"].(float64))
-		resource_url := strings.Replace(fmt.Sprintf("%s", d.Get("resource_url")), "<id>", mapped_resp["id"].(string), 1)
+		resource_url := getResourceUrl(fmt.Sprintf("%s", d.Get("resource_url")), mapped_resp["id"].(string), config)
 		d.Set("url", resource_url)
 	} else {
 		return fmt.Errorf("For the resource %s SignalFx returned status %d: \n%s", d.Get("name"), status_code, resp_body)
@@ -203,11 +490,61 @@ func resourceUpdate(url string, sfxToken string, payload []byte, d *schema.Resou
 	return nil
 }
 
+/*
+  custom_app_url replaces the scheme and host of the resource URLs, so it must not have a path or a fragment
+  (e.g. https://mycompany.signalfx.com for an SSO vanity domain)
+*/
+func validateAppUrl(v interface{}, k string) (we []string, errors []error) {
+	value := v.(string)
+	if value == "" {
+		return
+	}
+	appUrl, err := url.Parse(value)
+	if err != nil || (appUrl.Scheme != "https" && appUrl.Scheme != "http") || appUrl.Host == "" {
+		errors = append(errors, fmt.Errorf("%s not allowed; %s must be an http(s) URL, e.g. https://app.eu0.signalfx.com", value, k))
+		return
+	}
+	if strings.Trim(appUrl.Path, "/") != "" || appUrl.Fragment != "" || appUrl.RawQuery != "" {
+		errors = append(errors, fmt.Errorf("%s not allowed; %s must only have a scheme and a host, e.g. https://app.eu0.signalfx.com", value, k))
+	}
+	return
+}
+
+/*
+  Replaces "<id>" in the resource_url of a resource with its ID. URLs of the default application are
+  moved to custom_app_url when it is set, e.g. for organizations of another realm.
+*/
+func getResourceUrl(resourceUrl string, id string, config *signalformConfig) string {
+	if config.CustomAppURL != "" && strings.HasPrefix(resourceUrl, DEFAULT_APP_URL+"/") {
+		resourceUrl = strings.TrimRight(config.CustomAppURL, "/") + strings.TrimPrefix(resourceUrl, DEFAULT_APP_URL)
+	}
+	return strings.Replace(resourceUrl, "<id>", id, 1)
+}
+
+/*
+  Lets critical resources (e.g. paging detectors) refuse to be deleted until the flag is removed
+*/
+func protectFromDeletionSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+		Description: "(false by default) When true, deleting the resource fails. The flag must be removed and applied before deleting it",
+	}
+}
+
+func checkDeletionProtection(d *schema.ResourceData) error {
+	if d.Get("protect_from_deletion").(bool) {
+		return fmt.Errorf("%s %s is protected from deletion: remove protect_from_deletion and apply before deleting it", d.Get("name"), d.Id())
+	}
+	return nil
+}
+
 /*
   Deletes a resource.  If the resource does not exist, it will receive a 404, and carry on as usual.
 */
-func resourceDelete(url string, sfxToken string, d *schema.ResourceData) error {
-	status_code, resp_body, err := sendRequest("DELETE", url, sfxToken, nil)
+func resourceDelete(url string, config *signalformConfig, d *schema.ResourceData) error {
+	status_code, resp_body, err := sendRequest("DELETE", url, config, nil)
 	if err != nil {
 		return fmt.Errorf("Failed deleting resource  %s: %s", d.Get("name"), err.Error())
 	}
@@ -219,23 +556,58 @@ func resourceDelete(url string, sfxToken string, d *schema.ResourceData) error {
 	return nil
 }
 
+/*
+  Schema of the legend_options_fields blocks of time and list charts
+*/
+func legendOptionsFieldsSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:          schema.TypeList,
+		Optional:      true,
+		ConflictsWith: []string{"legend_fields_to_hide"},
+		Description:   "Columns of the data table legend, in the order they are shown",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"property": &schema.Schema{
+					Type:        schema.TypeString,
+					Required:    true,
+					Description: "The property (i.e. dimension name) of the column. Use metric or plot_label for the metric name and the plot label",
+				},
+				"enabled": &schema.Schema{
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     true,
+					Description: "(true by default) Whether the column is shown",
+				},
+			},
+		},
+	}
+}
+
 /*
 	Util method to get Legend Chart Options.
 */
 func getLegendOptions(d *schema.ResourceData) map[string]interface{} {
+	if fields, ok := d.GetOk("legend_options_fields"); ok {
+		fields := fields.([]interface{})
+		properties_opts := make([]map[string]interface{}, len(fields))
+		for i, field := range fields {
+			field := field.(map[string]interface{})
+			properties_opts[i] = map[string]interface{}{
+				"property": getLegendProperty(field["property"].(string)),
+				"enabled":  field["enabled"].(bool),
+			}
+		}
+		return map[string]interface{}{
+			"fields": properties_opts,
+		}
+	}
 	if properties, ok := d.GetOk("legend_fields_to_hide"); ok {
 		properties := properties.(*schema.Set).List()
 		legendOptions := make(map[string]interface{})
 		properties_opts := make([]map[string]interface{}, len(properties))
 		for i, property := range properties {
-			property := property.(string)
-			if property == "metric" {
-				property = "sf_originatingMetric"
-			} else if property == "plot_label" || property == "Plot Label" {
-				property = "sf_metric"
-			}
 			item := make(map[string]interface{})
-			item["property"] = property
+			item["property"] = getLegendProperty(property.(string))
 			item["enabled"] = false
 			properties_opts[i] = item
 		}
@@ -248,42 +620,15 @@ func getLegendOptions(d *schema.ResourceData) map[string]interface{} {
 }
 
 /*
-	Util method to validate SignalFx specific string format.
+  Maps the names of the metric and plot label columns to their SignalFx properties
 */
-func validateSignalfxRelativeTime(v interface{}, k string) (we []string, errors []error) {
-	ts := v.(string)
-
-	r, _ := regexp.Compile("-([0-9]+)[mhdw]")
-	if !r.MatchString(ts) {
-		errors = append(errors, fmt.Errorf("%s not allowed. Please use milliseconds from epoch or SignalFx time syntax (e.g. -5m, -1h)", ts))
+func getLegendProperty(property string) string {
+	if property == "metric" {
+		return "sf_originatingMetric"
+	} else if property == "plot_label" || property == "Plot Label" {
+		return "sf_metric"
 	}
-	return
-}
-
-/*
-*  Util method to convert from Signalfx string format to milliseconds
- */
-func fromRangeToMilliSeconds(timeRange string) (int, error) {
-	r := regexp.MustCompile("-([0-9]+)([mhdw])")
-	ss := r.FindStringSubmatch(timeRange)
-	var c int
-	switch ss[2] {
-	case "m":
-		c = 60 * 1000
-	case "h":
-		c = 60 * 60 * 1000
-	case "d":
-		c = 24 * 60 * 60 * 1000
-	case "w":
-		c = 7 * 24 * 60 * 60 * 1000
-	default:
-		c = 1
-	}
-	val, err := strconv.Atoi(ss[1])
-	if err != nil {
-		return -1, err
-	}
-	return val * c, nil
+	return property
 }
 
 /*
@@ -292,12 +637,7 @@ func fromRangeToMilliSeconds(timeRange string) (int, error) {
 func validatePerSignalColor(v interface{}, k string) (we []string, errors []error) {
 	value := v.(string)
 	if _, ok := PaletteColors[value]; !ok {
-		keys := make([]string, 0, len(PaletteColors))
-		for k := range PaletteColors {
-			keys = append(keys, k)
-		}
-		joinedColors := strings.Join(keys, ",")
-		errors = append(errors, fmt.Errorf("%s not allowed; must be either %s", value, joinedColors))
+		errors = append(errors, getPaletteColorError(value, PaletteColors))
 	}
 	return
 }
@@ -305,12 +645,106 @@ func validatePerSignalColor(v interface{}, k string) (we []string, errors []erro
 func validateFullPaletteColors(v interface{}, k string) (we []string, errors []error) {
 	value := v.(string)
 	if _, ok := FullPaletteColors[value]; !ok {
-		keys := make([]string, 0, len(FullPaletteColors))
-		for k := range FullPaletteColors {
-			keys = append(keys, k)
+		errors = append(errors, getPaletteColorError(value, FullPaletteColors))
+	}
+	return
+}
+
+/*
+  Checks the shape of a timezone of the IANA database (e.g. UTC, America/New_York). The timezone itself is
+  checked by SignalFx, since the database of the machine running Terraform may be missing or outdated.
+*/
+func validateTimezone(v interface{}, k string) (we []string, errors []error) {
+	value := v.(string)
+	if !timezoneRegexp.MatchString(value) {
+		errors = append(errors, fmt.Errorf("%s not allowed; must be a timezone like UTC or Europe/Paris", value))
+	}
+	return
+}
+
+/*
+  Filter values are matched exactly, or as a prefix when they end with a * wildcard (e.g. web-*).
+  Other patterns are caught here rather than by a failing apply, or worse a filter matching nothing.
+*/
+func validateFilterValue(v interface{}, k string) (we []string, errors []error) {
+	value := v.(string)
+	if value == "*" {
+		errors = append(errors, fmt.Errorf("%s not allowed; a wildcard must follow a prefix, e.g. web-*", value))
+	} else if strings.Contains(strings.TrimSuffix(value, "*"), "*") {
+		errors = append(errors, fmt.Errorf("%s not allowed; the * wildcard is only supported at the end of a value", value))
+	}
+	return
+}
+
+func validateJsonObject(v interface{}, k string) (we []string, errors []error) {
+	value := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(v.(string)), &value); err != nil {
+		errors = append(errors, fmt.Errorf("%s must be a JSON object: %s", k, err.Error()))
+	}
+	return
+}
+
+/*
+  Stores JSON attributes with sorted keys and without spaces, so that formatting changes are not reported as diffs
+*/
+func normalizeJsonState(v interface{}) string {
+	var decoded interface{}
+	if err := json.Unmarshal([]byte(v.(string)), &decoded); err != nil {
+		return v.(string)
+	}
+	normalized, _ := json.Marshal(decoded)
+	return string(normalized)
+}
+
+/*
+  SignalFx stores colors as indexes in its palette, there is no way to send an arbitrary color
+*/
+func getPaletteColorError(value string, palette map[string]int) error {
+	keys := make([]string, 0, len(palette))
+	for k := range palette {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	joinedColors := strings.Join(keys, ",")
+	if strings.HasPrefix(value, "#") {
+		return fmt.Errorf("%s not allowed; SignalFx does not support hex colors here, must be either %s", value, joinedColors)
+	}
+	return fmt.Errorf("%s not allowed; must be either %s", value, joinedColors)
+}
+
+/*
+  Checks the group_by properties of heatmap and table charts: grouping twice by the same property nests
+  each group in a single copy of itself
+*/
+func validateGroupBy(diff *schema.ResourceDiff, meta interface{}) error {
+	if !diff.NewValueKnown("group_by") {
+		return nil
+	}
+	return checkGroupBy(diff.Get("group_by").([]interface{}))
+}
+
+func checkGroupBy(groupBy []interface{}) error {
+	seen := make(map[string]bool)
+	for _, property := range groupBy {
+		property, _ := property.(string)
+		if property == "" {
+			return fmt.Errorf("group_by: properties must not be empty")
+		}
+		if seen[property] {
+			return fmt.Errorf("group_by: the property %s is listed several times", property)
 		}
-		joinedColors := strings.Join(keys, ",")
-		errors = append(errors, fmt.Errorf("%s not allowed; must be either %s", value, joinedColors))
+		seen[property] = true
+	}
+	return nil
+}
+
+/*
+  Validates the unit_prefix field of charts: Binary scales by 1024 (KiB, MiB), Metric by 1000 (kB, MB)
+*/
+func validateUnitPrefix(v interface{}, k string) (we []string, errors []error) {
+	value := v.(string)
+	if value != "Metric" && value != "Binary" {
+		errors = append(errors, fmt.Errorf("%s not allowed; must be one of: Metric, Binary", value))
 	}
 	return
 }
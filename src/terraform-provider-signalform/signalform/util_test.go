@@ -2,10 +2,12 @@ package signalform
 
 import (
 	"fmt"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -17,18 +19,34 @@ func TestSendRequestSuccess(t *testing.T) {
 	}))
 	defer server.Close()
 
-	status_code, body, err := sendRequest("GET", server.URL, "token", nil)
+	status_code, body, err := sendRequest("GET", server.URL, &signalformConfig{AuthToken: "token"}, nil)
 	assert.Equal(t, 200, status_code)
 	assert.Equal(t, "Test Response\n", string(body))
 	assert.Nil(t, err)
 }
 
+func TestSendRequestHeaders(t *testing.T) {
+	var headers http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header
+		w.WriteHeader(200)
+	}))
+	defer server.Close()
+
+	sendRequest("GET", server.URL, &signalformConfig{AuthToken: "token"}, nil)
+	assert.Equal(t, "terraform-provider-signalform/dev", headers.Get("User-Agent"))
+	assert.Equal(t, "", headers.Get("X-SF-Request-Tag"))
+
+	sendRequest("GET", server.URL, &signalformConfig{AuthToken: "token", RequestTag: "deploy-pipeline"}, nil)
+	assert.Equal(t, "deploy-pipeline", headers.Get("X-SF-Request-Tag"))
+}
+
 func TestSendRequestResponseNotFound(t *testing.T) {
 	// Handler returns 404 page not found
 	server := httptest.NewServer(http.NotFoundHandler())
 	defer server.Close()
 
-	status_code, body, err := sendRequest("POST", server.URL, "token", nil)
+	status_code, body, err := sendRequest("POST", server.URL, &signalformConfig{AuthToken: "token"}, nil)
 	assert.Equal(t, 404, status_code)
 	assert.Contains(t, string(body), "page not found")
 	assert.Nil(t, err)
@@ -36,41 +54,54 @@ func TestSendRequestResponseNotFound(t *testing.T) {
 
 func TestSendRequestFail(t *testing.T) {
 	// Client will fail to send due to invalid URL
-	status_code, body, err := sendRequest("GET", "", "token", nil)
+	status_code, body, err := sendRequest("GET", "", &signalformConfig{AuthToken: "token"}, nil)
 	assert.Equal(t, -1, status_code)
 	assert.Nil(t, body)
 	assert.Contains(t, err.Error(), "Failed sending GET request")
 }
 
-func TestValidateSignalfxRelativeTimeMinutes(t *testing.T) {
-	_, errors := validateSignalfxRelativeTime("-5m", "time_range")
-	assert.Equal(t, 0, len(errors))
-}
-
-func TestValidateSignalfxRelativeTimeHours(t *testing.T) {
-	_, errors := validateSignalfxRelativeTime("-5h", "time_range")
-	assert.Equal(t, 0, len(errors))
-}
+func TestResourceRead(t *testing.T) {
+	response := `{"id":"abc","lastUpdated":1500000000000,"options":{"type":"TimeSeriesChart"}}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+		fmt.Fprintln(w, response)
+	}))
+	defer server.Close()
 
-func TestValidateSignalfxRelativeTimeDays(t *testing.T) {
-	_, errors := validateSignalfxRelativeTime("-5d", "time_range")
-	assert.Equal(t, 0, len(errors))
-}
+	d := timeChartResource().TestResourceData()
+	d.Set("synced", true)
+	d.Set("resource_url", CHART_URL)
+	assert.Nil(t, resourceRead(server.URL, &signalformConfig{AuthToken: "token"}, d))
+	assert.Equal(t, false, d.Get("synced"))
+	assert.Equal(t, 1500000000000.0, d.Get("last_updated"))
+	assert.Equal(t, "https://app.signalfx.com/#/chart/abc", d.Get("url"))
 
-func TestValidateSignalfxRelativeTimeWeeks(t *testing.T) {
-	_, errors := validateSignalfxRelativeTime("-5w", "time_range")
-	assert.Equal(t, 0, len(errors))
+	// Objects without lastUpdated are considered unchanged
+	response = `{"id":"abc"}`
+	d.Set("synced", true)
+	assert.Nil(t, resourceRead(server.URL, &signalformConfig{AuthToken: "token"}, d))
+	assert.Equal(t, true, d.Get("synced"))
 }
 
-func TestValidateSignalfxRelativeTimeNotAllowed(t *testing.T) {
-	_, errors := validateSignalfxRelativeTime("-5M", "time_range")
-	assert.Equal(t, 1, len(errors))
-}
+func TestResourceReadWithState(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+		fmt.Fprintln(w, `{"id":"abc","lastUpdated":1500000000000,"name":"Latency"}`)
+	}))
+	defer server.Close()
 
-func TestConversionSignalfxrealtiveTimeIntoMs(t *testing.T) {
-	ms, err := fromRangeToMilliSeconds("-15m")
-	assert.Equal(t, 900000, ms)
-	assert.Nil(t, err)
+	d := timeChartResource().TestResourceData()
+	d.Set("synced", true)
+	d.Set("resource_url", CHART_URL)
+	var object map[string]interface{}
+	assert.Nil(t, resourceReadWithState(server.URL, &signalformConfig{AuthToken: "token"}, d, func(resp map[string]interface{}, d *schema.ResourceData) error {
+		object = resp
+		return nil
+	}))
+	assert.Equal(t, map[string]interface{}{"id": "abc", "lastUpdated": 1500000000000.0, "name": "Latency"}, object)
+	assert.Equal(t, false, d.Get("synced"))
+	assert.Equal(t, 1500000000000.0, d.Get("last_updated"))
+	assert.Equal(t, "https://app.signalfx.com/#/chart/abc", d.Get("url"))
 }
 
 func TestValidateSortByAscending(t *testing.T) {
@@ -93,7 +124,186 @@ func TestValidateFullPaletteColorsFail(t *testing.T) {
 	assert.Equal(t, 1, len(errors))
 }
 
+func TestValidatePerSignalColorHex(t *testing.T) {
+	_, errors := validatePerSignalColor("#ff0000", "color")
+	assert.Equal(t, 1, len(errors))
+	assert.Contains(t, errors[0].Error(), "does not support hex colors")
+}
+
+func TestValidateSortByNoProperty(t *testing.T) {
+	_, errors := validateSortBy("-", "sort_by")
+	assert.Equal(t, 1, len(errors))
+}
+
 func TestValidateSortByNoDirection(t *testing.T) {
 	_, errors := validateSortBy("foo", "sort_by")
 	assert.Equal(t, 1, len(errors))
 }
+
+func TestProgramTextPublishes(t *testing.T) {
+	assert.True(t, programTextPublishes("data('cpu.utilization').mean().publish(label='A')"))
+	assert.True(t, programTextPublishes("A = data('cpu.utilization')\npublish (A)"))
+	assert.False(t, programTextPublishes("A = data('cpu.utilization').mean()"))
+}
+
+func TestIsUnsupportedEndpoint(t *testing.T) {
+	assert.True(t, isUnsupportedEndpoint(404, []byte("404 page not found")))
+	assert.True(t, isUnsupportedEndpoint(403, []byte(`{"message":"Feature slo is not enabled for this organization"}`)))
+	assert.False(t, isUnsupportedEndpoint(404, []byte(`{"message":"Dashboard ABC not found"}`)))
+	assert.False(t, isUnsupportedEndpoint(403, []byte(`{"message":"Invalid token"}`)))
+	assert.False(t, isUnsupportedEndpoint(403, []byte(`{"message":"Token lacks the permission to use the feature"}`)))
+	assert.False(t, isUnsupportedEndpoint(403, []byte(`{"message":"Dashboard ABC is not enabled for writes by this user"}`)))
+	assert.False(t, isUnsupportedEndpoint(500, []byte("page not found")))
+}
+
+func TestNormalizeJsonState(t *testing.T) {
+	assert.Equal(t, `{"a":1,"b":[true]}`, normalizeJsonState("{\n  \"b\": [true],\n  \"a\": 1\n}"))
+}
+
+func TestValidateJsonObject(t *testing.T) {
+	_, errors := validateJsonObject(`{"name": "dashboard"}`, "raw_json")
+	assert.Equal(t, 0, len(errors))
+	_, errors = validateJsonObject(`["name"]`, "raw_json")
+	assert.Equal(t, 1, len(errors))
+}
+
+func TestValidateTimezone(t *testing.T) {
+	for _, value := range []string{"UTC", "Europe/Paris", "America/Argentina/Buenos_Aires", "Etc/GMT+2"} {
+		_, errors := validateTimezone(value, "timezone")
+		assert.Equal(t, 0, len(errors), value)
+	}
+	for _, value := range []string{"", "+02:00", "Europe Paris"} {
+		_, errors := validateTimezone(value, "timezone")
+		assert.Equal(t, 1, len(errors), value)
+	}
+}
+
+func TestValidateFilterValue(t *testing.T) {
+	for _, value := range []string{"web-1", "web-*"} {
+		_, errors := validateFilterValue(value, "values")
+		assert.Equal(t, 0, len(errors), value)
+	}
+	for _, value := range []string{"*", "*-web", "web-*-1", "web-**"} {
+		_, errors := validateFilterValue(value, "values")
+		assert.Equal(t, 1, len(errors), value)
+	}
+}
+
+func TestGetResourceUrl(t *testing.T) {
+	assert.Equal(t, "https://app.signalfx.com/#/chart/abc", getResourceUrl(CHART_URL, "abc", &signalformConfig{}))
+
+	config := &signalformConfig{CustomAppURL: "https://app.eu0.signalfx.com/"}
+	assert.Equal(t, "https://app.eu0.signalfx.com/#/chart/abc", getResourceUrl(CHART_URL, "abc", config))
+	assert.Equal(t, "https://signalfx.example.com/chart/abc", getResourceUrl("https://signalfx.example.com/chart/<id>", "abc", config))
+}
+
+func TestValidateAppUrl(t *testing.T) {
+	for _, value := range []string{"", "https://app.eu0.signalfx.com", "https://mycompany.signalfx.com/"} {
+		_, errors := validateAppUrl(value, "custom_app_url")
+		assert.Equal(t, 0, len(errors), value)
+	}
+	for _, value := range []string{"app.eu0.signalfx.com", "https://app.signalfx.com/#/dashboard", "https://app.signalfx.com/sso"} {
+		_, errors := validateAppUrl(value, "custom_app_url")
+		assert.Equal(t, 1, len(errors), value)
+	}
+}
+
+func TestCheckDeletionProtection(t *testing.T) {
+	d := detectorResource().TestResourceData()
+	d.SetId("abc")
+	d.Set("name", "Paging")
+	assert.Nil(t, checkDeletionProtection(d))
+
+	d.Set("protect_from_deletion", true)
+	err := detectorDelete(d, nil)
+	assert.Contains(t, err.Error(), "Paging abc is protected from deletion")
+}
+
+func TestGetColorScaleOptions(t *testing.T) {
+	d := singleValueChartResource().TestResourceData()
+	d.Set("color_scale", []interface{}{
+		map[string]interface{}{"gt": 60.0, "gte": math.MaxFloat32, "lt": math.MaxFloat32, "lte": math.MaxFloat32, "color": "green"},
+	})
+
+	options := getColorScaleOptions(d)
+	assert.Equal(t, 1, len(options))
+	scale := options[0].(map[string]interface{})
+	assert.Equal(t, 60.0, scale["gt"])
+	assert.Equal(t, 14, scale["paletteIndex"])
+	_, ok := scale["lte"]
+	assert.False(t, ok)
+}
+
+func TestCheckColorScale(t *testing.T) {
+	unset := func(scale map[string]interface{}) map[string]interface{} {
+		for _, bound := range []string{"gt", "gte", "lt", "lte"} {
+			if _, ok := scale[bound]; !ok {
+				scale[bound] = float64(math.MaxFloat32)
+			}
+		}
+		return scale
+	}
+
+	assert.Nil(t, checkColorScale([]interface{}{
+		unset(map[string]interface{}{"gt": 60.0, "color": "green"}),
+		unset(map[string]interface{}{"lte": 60.0, "color": "yellow"}),
+	}))
+	assert.Contains(t, checkColorScale([]interface{}{
+		unset(map[string]interface{}{"color": "green"}),
+	}).Error(), "at least one of gt, gte, lt or lte")
+	assert.Contains(t, checkColorScale([]interface{}{
+		unset(map[string]interface{}{"gt": 60.0, "gte": 50.0, "color": "green"}),
+	}).Error(), "both gt and gte")
+	assert.Contains(t, checkColorScale([]interface{}{
+		unset(map[string]interface{}{"lt": 60.0, "lte": 50.0, "color": "green"}),
+	}).Error(), "both lt and lte")
+}
+
+func TestValidateSecondaryVisualization(t *testing.T) {
+	for _, value := range []string{"None", "Radial", "Linear", "Sparkline"} {
+		_, errors := validateSecondaryVisualization(value, "secondary_visualization")
+		assert.Equal(t, 0, len(errors))
+	}
+	_, errors := validateSecondaryVisualization("sparkline", "secondary_visualization")
+	assert.Equal(t, 1, len(errors))
+}
+
+func TestValidateColorBy(t *testing.T) {
+	for _, value := range []string{"Metric", "Dimension", "Scale"} {
+		_, errors := validateColorBy(value, "color_by")
+		assert.Equal(t, 0, len(errors))
+	}
+
+	_, errors := validateColorBy("Value", "color_by")
+	assert.Equal(t, 1, len(errors))
+	assert.Contains(t, errors[0].Error(), "did you mean Scale?")
+
+	_, errors = validateColorBy("dimension", "color_by")
+	assert.Equal(t, 1, len(errors))
+}
+
+func TestValidateUnitPrefix(t *testing.T) {
+	_, errors := validateUnitPrefix("Binary", "unit_prefix")
+	assert.Equal(t, 0, len(errors))
+	_, errors = validateUnitPrefix("Metric", "unit_prefix")
+	assert.Equal(t, 0, len(errors))
+	_, errors = validateUnitPrefix("binary", "unit_prefix")
+	assert.Equal(t, 1, len(errors))
+}
+
+func TestCheckGroupBy(t *testing.T) {
+	assert.Nil(t, checkGroupBy([]interface{}{"aws_availability_zone", "host"}))
+	assert.Contains(t, checkGroupBy([]interface{}{"host", "host"}).Error(), "listed several times")
+	assert.Contains(t, checkGroupBy([]interface{}{""}).Error(), "must not be empty")
+}
+
+func TestFilterObjectsByTags(t *testing.T) {
+	objects := []map[string]interface{}{
+		map[string]interface{}{"id": "1", "tags": []interface{}{"a", "b"}},
+		map[string]interface{}{"id": "2", "tags": []interface{}{"a"}},
+		map[string]interface{}{"id": "3"},
+	}
+	assert.Equal(t, 3, len(filterObjectsByTags(objects, []string{})))
+	assert.Equal(t, 2, len(filterObjectsByTags(objects, []string{"a"})))
+	assert.Equal(t, "1", filterObjectsByTags(objects, []string{"a", "b"})[0]["id"])
+}